@@ -0,0 +1,35 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CVEAllowlist is a list of CVEs which are ignored when checking images for vulnerabilities,
+// e.g. to prevent vulnerable images from being pulled.
+type CVEAllowlist struct {
+	// +optional
+	Items []CVEAllowlistItem `json:"items,omitempty"`
+}
+
+// CVEAllowlistItem is a single CVE of a CVE allowlist.
+type CVEAllowlistItem struct {
+	// The ID of the CVE, e.g. "CVE-2019-10164".
+	// +kubebuilder:validation:Pattern=`^CVE-\d{4}-\d{4,}$`
+	ID string `json:"id"`
+
+	// ExpiresAt is the time after which the CVE is no longer allowlisted.
+	// The CVE does not expire if unset.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// CVEAllowlistStatus describes the state of a CVE allowlist in Harbor.
+type CVEAllowlistStatus struct {
+	// Active lists the IDs of the CVEs which are currently allowlisted in Harbor.
+	// +optional
+	Active []string `json:"active,omitempty"`
+
+	// Expired lists the items which have expired and have therefore been removed from the allowlist in Harbor.
+	// +optional
+	Expired []CVEAllowlistItem `json:"expired,omitempty"`
+}
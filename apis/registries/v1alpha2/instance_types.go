@@ -1,8 +1,14 @@
 package v1alpha2
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	helmclient "github.com/mittwald/go-helm-client"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,6 +22,111 @@ const (
 	InstanceStatusPhaseError       InstanceStatusPhaseName = "Error"
 )
 
+// AnnotationTriggerGarbageCollection is the annotation of an instance starting an immediate garbage collection.
+// The annotation is removed once the garbage collection job has been accepted by Harbor.
+const AnnotationTriggerGarbageCollection = "registries.mittwald.de/trigger-gc"
+
+// Condition types reported in InstanceStatus.Conditions.
+const (
+	// InstanceConditionHelmReleaseReady reports whether the Harbor helm release has been applied successfully.
+	InstanceConditionHelmReleaseReady = "HelmReleaseReady"
+	// InstanceConditionHarborAPIHealthy reports whether the Harbor API reports all components as healthy.
+	InstanceConditionHarborAPIHealthy = "HarborAPIHealthy"
+	// InstanceConditionGarbageCollectionSynced reports whether the garbage collection schedule is in sync.
+	InstanceConditionGarbageCollectionSynced = "GarbageCollectionSynced"
+	// InstanceConditionConfigurationSynced reports whether the Harbor system configuration is in sync.
+	InstanceConditionConfigurationSynced = "ConfigurationSynced"
+	// InstanceConditionOIDCSynced reports whether the OIDC authentication settings are in sync.
+	InstanceConditionOIDCSynced = "OIDCSynced"
+	// InstanceConditionLDAPSynced reports whether the LDAP authentication settings are in sync.
+	InstanceConditionLDAPSynced = "LDAPSynced"
+	// InstanceConditionLDAPReachable reports whether Harbor can connect to the LDAP server.
+	InstanceConditionLDAPReachable = "LDAPReachable"
+	// InstanceConditionCVEAllowlistSynced reports whether the system CVE allowlist is in sync.
+	InstanceConditionCVEAllowlistSynced = "CVEAllowlistSynced"
+	// InstanceConditionScanAllSynced reports whether the scan all schedule is in sync.
+	InstanceConditionScanAllSynced = "ScanAllSynced"
+	// InstanceConditionAuditLogPurgeSynced reports whether the audit log purge schedule is in sync.
+	InstanceConditionAuditLogPurgeSynced = "AuditLogPurgeSynced"
+	// InstanceConditionDriftDetected reports whether the deployed helm release has diverged from the desired chart spec.
+	InstanceConditionDriftDetected = "DriftDetected"
+	// InstanceConditionDegraded reports whether any of the Harbor components is unhealthy.
+	InstanceConditionDegraded = "Degraded"
+	// InstanceConditionReady summarizes the conditions above.
+	InstanceConditionReady = "Ready"
+)
+
+// Condition reasons reported in InstanceStatus.Conditions.
+const (
+	InstanceReasonInstalling                 = "Installing"
+	InstanceReasonInstallSucceeded           = "InstallSucceeded"
+	InstanceReasonInstallFailed              = "InstallFailed"
+	InstanceReasonTerminating                = "Terminating"
+	InstanceReasonHealthy                    = "Healthy"
+	InstanceReasonUnhealthy                  = "Unhealthy"
+	InstanceReasonAPIUnreachable             = "APIUnreachable"
+	InstanceReasonComponentsUnhealthy        = "ComponentsUnhealthy"
+	InstanceReasonComponentsHealthy          = "ComponentsHealthy"
+	InstanceReasonScheduleSynced             = "ScheduleSynced"
+	InstanceReasonScheduleSyncFailed         = "ScheduleSyncFailed"
+	InstanceReasonReady                      = "Ready"
+	InstanceReasonHelmReleaseNotReady        = "HelmReleaseNotReady"
+	InstanceReasonHarborAPIUnhealthy         = "HarborAPIUnhealthy"
+	InstanceReasonGarbageCollectionNotSynced = "GarbageCollectionNotSynced"
+	InstanceReasonConfigurationSynced        = "ConfigurationSynced"
+	InstanceReasonConfigurationRejected      = "ConfigurationRejected"
+	InstanceReasonConfigurationSyncFailed    = "ConfigurationSyncFailed"
+	InstanceReasonConfigurationNotSynced     = "ConfigurationNotSynced"
+	InstanceReasonOIDCSynced                 = "OIDCSynced"
+	InstanceReasonOIDCSyncFailed             = "OIDCSyncFailed"
+	InstanceReasonOIDCNotSynced              = "OIDCNotSynced"
+	InstanceReasonLDAPSynced                 = "LDAPSynced"
+	InstanceReasonLDAPSyncFailed             = "LDAPSyncFailed"
+	InstanceReasonLDAPNotSynced              = "LDAPNotSynced"
+	InstanceReasonLDAPPingSucceeded          = "LDAPPingSucceeded"
+	InstanceReasonLDAPPingFailed             = "LDAPPingFailed"
+	InstanceReasonCVEAllowlistSynced         = "CVEAllowlistSynced"
+	InstanceReasonCVEAllowlistSyncFailed     = "CVEAllowlistSyncFailed"
+	InstanceReasonCVEAllowlistNotSynced      = "CVEAllowlistNotSynced"
+	InstanceReasonScanAllNotSynced           = "ScanAllNotSynced"
+	InstanceReasonAuditLogPurgeNotSynced     = "AuditLogPurgeNotSynced"
+	InstanceReasonDriftDetected              = "DriftDetected"
+	InstanceReasonNoDrift                    = "NoDrift"
+	InstanceReasonDriftCheckFailed           = "DriftCheckFailed"
+	InstanceReasonHelmReleaseRecovered       = "HelmReleaseRecovered"
+	InstanceReasonHelmReleaseRecoveryFailed  = "HelmReleaseRecoveryFailed"
+)
+
+// Instance types, set via InstanceSpec.Type.
+const (
+	// InstanceTypeManual denotes an instance whose Harbor installation is managed by the operator via helm.
+	InstanceTypeManual = "manual"
+	// InstanceTypeExternal denotes an instance pointing to an externally managed Harbor installation,
+	// which is only accessed via its API.
+	InstanceTypeExternal = "external"
+)
+
+// HelmReleaseRecoveryAction is the action taken to recover a helm release stuck in a pending state.
+type HelmReleaseRecoveryAction string
+
+const (
+	// HelmReleaseRecoveryActionRolledBack denotes a stuck helm release that was rolled back
+	// to its previous revision.
+	HelmReleaseRecoveryActionRolledBack HelmReleaseRecoveryAction = "RolledBack"
+	// HelmReleaseRecoveryActionMarkedFailed denotes a stuck helm release that was marked as failed,
+	// as there was no previous revision to roll back to.
+	HelmReleaseRecoveryActionMarkedFailed HelmReleaseRecoveryAction = "MarkedFailed"
+)
+
+// LDAPSearchScope is the scope of LDAP searches.
+type LDAPSearchScope string
+
+const (
+	LDAPSearchScopeBase     LDAPSearchScope = "Base"
+	LDAPSearchScopeOneLevel LDAPSearchScope = "OneLevel"
+	LDAPSearchScopeSubtree  LDAPSearchScope = "Subtree"
+)
+
 type ScheduleType string
 
 const (
@@ -27,11 +138,26 @@ const (
 	ScheduleTypeNone     ScheduleType = "None"
 )
 
+// AuditLogOperation is an operation recorded in the Harbor audit log.
+// +kubebuilder:validation:Enum=create;delete;pull
+type AuditLogOperation string
+
+const (
+	AuditLogOperationCreate AuditLogOperation = "create"
+	AuditLogOperationDelete AuditLogOperation = "delete"
+	AuditLogOperationPull   AuditLogOperation = "pull"
+)
+
 // Instance is the Schema for the instances API
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=instances,scope=Namespaced,shortName=harborinstance;harbor
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase.name",description="phase"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="ready condition"
 // +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".spec.instanceURL", description="harbor instance url"
+// +kubebuilder:printcolumn:name="Chart Version",type="string",JSONPath=".status.helmRelease.chartVersion",description="deployed helm chart version"
+// +kubebuilder:printcolumn:name="App Version",type="string",JSONPath=".status.helmRelease.appVersion",description="deployed harbor version"
+// +kubebuilder:printcolumn:name="Release Status",type="string",JSONPath=".status.helmRelease.status",description="helm release status",priority=1
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".status.helmRelease.revision",description="helm release revision",priority=1
 // +kubebuilder:object:root=true
 type Instance struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -54,18 +180,178 @@ func init() {
 }
 
 // InstanceSpec defines the desired state of Instance.
+// +kubebuilder:validation:XValidation:rule="!has(self.oidc) || !has(self.ldap)",message="oidc and ldap are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="self.type == 'external' || has(self.helmChart)",message="helmChart is required unless the instance is of type external"
 type InstanceSpec struct {
 	Name string `json:"name"`
 	// can't use the resulting string-type so this is a simple string and will be casted to an OperatorType in the resolver:
 	// error: Hit an unsupported type invalid type for invalid type
+	// Instances of type "external" point to an externally managed Harbor installation. For these, no helm release
+	// is installed and the instance is considered as installed as soon as its API reports as healthy.
+	// Any other type denotes a Harbor installation managed by the operator via the given helm chart.
 	Type string `json:"type"`
 
 	InstanceURL string `json:"instanceURL"`
 
-	HelmChart *InstanceHelmChartSpec `json:"helmChart"`
+	// HelmChart is the helm chart used to install Harbor.
+	// Required unless the instance is of type "external".
+	// +kubebuilder:validation:Optional
+	HelmChart *InstanceHelmChartSpec `json:"helmChart,omitempty"`
+
+	// AdminCredentials references the secret holding the credentials of the Harbor admin user,
+	// which are used by the operator to access the Harbor API.
+	// Defaults to the password stored in the secret "<instance-name>-harbor-core" created by the Harbor helm chart,
+	// using the username "admin".
+	// +kubebuilder:validation:Optional
+	AdminCredentials *InstanceAdminCredentials `json:"adminCredentials,omitempty"`
+
+	// APIClient configures the client used by the operator to access the Harbor API.
+	// +kubebuilder:validation:Optional
+	APIClient *InstanceAPIClientSpec `json:"apiClient,omitempty"`
+
+	// ReadinessTimeout is the maximum duration to wait for Harbor to report as healthy
+	// after the helm release has been applied, before the instance is moved into the "Error" phase.
+	// Defaults to 10 minutes.
+	// +kubebuilder:validation:Optional
+	ReadinessTimeout *metav1.Duration `json:"readinessTimeout,omitempty"`
 
 	// +kubebuilder:validation:Optional
 	GarbageCollection *GarbageCollection `json:"garbageCollection,omitempty"`
+
+	// ScanAll holds the schedule in which all artifacts are scanned for vulnerabilities.
+	// +kubebuilder:validation:Optional
+	ScanAll *ScanAll `json:"scanAll,omitempty"`
+
+	// AuditLogPurge holds the schedule in which outdated audit logs are purged.
+	// +kubebuilder:validation:Optional
+	AuditLogPurge *AuditLogPurge `json:"auditLogPurge,omitempty"`
+
+	// Configuration holds Harbor system configuration items, keyed by the names used by the
+	// Harbor configurations API, e.g. "project_creation_restriction" or "robot_token_duration".
+	// The given items are enforced by the operator, items which are not given are left untouched.
+	// +kubebuilder:validation:Optional
+	Configuration map[string]apiextensionsv1.JSON `json:"configuration,omitempty"`
+
+	// OIDC configures Harbor to authenticate users via an OpenID Connect provider.
+	// Setting it switches the auth mode of Harbor to "oidc_auth".
+	// +kubebuilder:validation:Optional
+	OIDC *InstanceOIDCSpec `json:"oidc,omitempty"`
+
+	// LDAP configures Harbor to authenticate users against an LDAP server.
+	// Setting it switches the auth mode of Harbor to "ldap_auth".
+	// +kubebuilder:validation:Optional
+	LDAP *InstanceLDAPSpec `json:"ldap,omitempty"`
+
+	// CVEAllowlist is the system-wide CVE allowlist of Harbor, which applies to all projects reusing it.
+	// Once set, the system allowlist is enforced by the operator. Expired items are removed from it.
+	// +kubebuilder:validation:Optional
+	CVEAllowlist *CVEAllowlist `json:"cveAllowlist,omitempty"`
+}
+
+// InstanceLDAPSpec holds the settings of an LDAP server used to authenticate Harbor users.
+type InstanceLDAPSpec struct {
+	// URL of the LDAP server, e.g. "ldaps://ldap.example.com".
+	URL string `json:"url"`
+
+	// BaseDN to search users in.
+	BaseDN string `json:"baseDN"`
+
+	// Filter applied when searching users.
+	// +kubebuilder:validation:Optional
+	Filter string `json:"filter,omitempty"`
+
+	// UID is the attribute used to match a user during authentication. Defaults to "uid".
+	// +kubebuilder:validation:Optional
+	UID string `json:"uid,omitempty"`
+
+	// Scope of user searches. Defaults to "Subtree".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Base;OneLevel;Subtree
+	Scope LDAPSearchScope `json:"scope,omitempty"`
+
+	// BindDN is the DN of the user binding to the LDAP server to search users and groups.
+	// +kubebuilder:validation:Optional
+	BindDN string `json:"bindDN,omitempty"`
+
+	// BindPasswordRef references the secret key holding the password of the BindDN user.
+	// +kubebuilder:validation:Optional
+	BindPasswordRef *corev1.SecretKeySelector `json:"bindPasswordRef,omitempty"`
+
+	// GroupBaseDN to search groups in.
+	// +kubebuilder:validation:Optional
+	GroupBaseDN string `json:"groupBaseDN,omitempty"`
+
+	// GroupFilter applied when searching groups.
+	// +kubebuilder:validation:Optional
+	GroupFilter string `json:"groupFilter,omitempty"`
+
+	// GroupAttributeName is the attribute holding the name of a group, e.g. "cn".
+	// +kubebuilder:validation:Optional
+	GroupAttributeName string `json:"groupAttributeName,omitempty"`
+
+	// GroupAdminDN is the DN of the group whose members are granted Harbor admin privileges.
+	// +kubebuilder:validation:Optional
+	GroupAdminDN string `json:"groupAdminDN,omitempty"`
+
+	// GroupMembershipAttribute is the user attribute listing the groups of a user. Defaults to "memberof".
+	// +kubebuilder:validation:Optional
+	GroupMembershipAttribute string `json:"groupMembershipAttribute,omitempty"`
+
+	// GroupScope of group searches. Defaults to "Subtree".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Base;OneLevel;Subtree
+	GroupScope LDAPSearchScope `json:"groupScope,omitempty"`
+
+	// VerifyCert enables the verification of the certificate of the LDAP server. Defaults to true.
+	// +kubebuilder:validation:Optional
+	VerifyCert *bool `json:"verifyCert,omitempty"`
+}
+
+// InstanceOIDCSpec holds the settings of an OpenID Connect provider used to authenticate Harbor users.
+type InstanceOIDCSpec struct {
+	// Name of the OIDC provider, as shown on the Harbor login page.
+	Name string `json:"name"`
+
+	// Endpoint is the URL of the OIDC provider.
+	Endpoint string `json:"endpoint"`
+
+	// ClientID registered at the OIDC provider.
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef references the secret key holding the client secret registered at the OIDC provider.
+	ClientSecretRef corev1.SecretKeySelector `json:"clientSecretRef"`
+
+	// Scopes requested from the OIDC provider. Defaults to "openid" and "offline_access".
+	// +kubebuilder:validation:Optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// GroupsClaim is the name of the claim holding the groups of a user.
+	// +kubebuilder:validation:Optional
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// AdminGroup is the name of the group whose members are granted Harbor admin privileges.
+	// +kubebuilder:validation:Optional
+	AdminGroup string `json:"adminGroup,omitempty"`
+
+	// GroupFilter is a regular expression filtering the groups of a user.
+	// +kubebuilder:validation:Optional
+	GroupFilter string `json:"groupFilter,omitempty"`
+
+	// UserClaim is the name of the claim used as username. Defaults to the "name" claim.
+	// +kubebuilder:validation:Optional
+	UserClaim string `json:"userClaim,omitempty"`
+
+	// AutoOnboard skips the onboarding screen, using the UserClaim as username.
+	// +kubebuilder:validation:Optional
+	AutoOnboard bool `json:"autoOnboard,omitempty"`
+
+	// VerifyCert enables the verification of the certificate of the OIDC provider. Defaults to true.
+	// +kubebuilder:validation:Optional
+	VerifyCert *bool `json:"verifyCert,omitempty"`
+
+	// ExtraRedirectParams are additional parameters passed to the authorization endpoint of the OIDC provider.
+	// +kubebuilder:validation:Optional
+	ExtraRedirectParams map[string]string `json:"extraRedirectParams,omitempty"`
 }
 
 // GarbageCollection holds request information for a garbage collection schedule.
@@ -78,14 +364,222 @@ type GarbageCollection struct {
 
 	// +kubebuilder:validation:Optional
 	ScheduleType ScheduleType `json:"scheduleType,omitempty"`
+
+	// DryRun makes the garbage collection only estimate the reclaimable space, without deleting anything.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Workers is the number of workers deleting blobs in parallel. Defaults to the Harbor default of 1 worker.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5
+	// +kubebuilder:validation:Optional
+	Workers *int32 `json:"workers,omitempty"`
+
+	// HistoryLimit is the number of the latest garbage collection executions reported in the instance status.
+	// Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
 }
 
+// ScanAll holds request information for a schedule scanning all artifacts for vulnerabilities.
+type ScanAll struct {
+	// +kubebuilder:validation:Optional
+	Cron string `json:"cron,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ScheduleType ScheduleType `json:"scheduleType,omitempty"`
+}
+
+// AuditLogPurge holds request information for a schedule purging outdated audit logs.
+type AuditLogPurge struct {
+	// +kubebuilder:validation:Optional
+	Cron string `json:"cron,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ScheduleType ScheduleType `json:"scheduleType,omitempty"`
+
+	// RetentionHours is the number of hours audit logs are retained for before they are purged.
+	// +kubebuilder:validation:Minimum=1
+	RetentionHours int64 `json:"retentionHours"`
+
+	// IncludeOperations are the operations whose audit logs are purged.
+	// Defaults to all operations ("create", "delete" and "pull").
+	// +kubebuilder:validation:Optional
+	IncludeOperations []AuditLogOperation `json:"includeOperations,omitempty"`
+}
+
+// InstanceAdminCredentials references the credentials of the Harbor admin user.
+type InstanceAdminCredentials struct {
+	// SecretRef references the secret holding the admin credentials.
+	// Defaults to the secret "<instance-name>-harbor-core".
+	// +kubebuilder:validation:Optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// UsernameKey is the key of the secret holding the name of the admin user.
+	// If omitted, the username "admin" is used.
+	// +kubebuilder:validation:Optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// PasswordKey is the key of the secret holding the password of the admin user.
+	// Defaults to "HARBOR_ADMIN_PASSWORD".
+	// +kubebuilder:validation:Optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// InstanceAPIClientSpec configures the client used to access the Harbor API.
+type InstanceAPIClientSpec struct {
+	// CABundle references PEM-encoded CA certificates used to verify the certificate of the Harbor API,
+	// in addition to the system trust store.
+	// +kubebuilder:validation:Optional
+	CABundle *InstanceCABundleSource `json:"caBundle,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificate of the Harbor API.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// Timeout of requests to the Harbor API. Defaults to 10 seconds.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// PageSize used when listing resources via the Harbor API. Defaults to 10.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	PageSize int64 `json:"pageSize,omitempty"`
+}
+
+// InstanceCABundleSource references a key of either a ConfigMap or a Secret holding a CA bundle.
+type InstanceCABundleSource struct {
+	// +kubebuilder:validation:Optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// IsExternal returns true if the instance points to an externally managed Harbor installation.
+func (spec *InstanceSpec) IsExternal() bool {
+	return spec.Type == InstanceTypeExternal
+}
+
+// InstanceHelmChartSpec is the helm chart used to install Harbor.
+// Timeout and Wait share their JSON names with fields of the embedded helmclient.ChartSpec and win over them,
+// the embedded fields are only set by the operator before running helm operations.
 type InstanceHelmChartSpec struct {
 	helmclient.ChartSpec `json:",inline"`
 
 	// set additional chart values from secret
 	// +kubebuilder:validation:Optional
 	SecretValues *InstanceHelmChartSecretValues `json:"secretValues,omitempty"`
+
+	// ValuesFrom lists ConfigMaps and Secrets holding additional chart values.
+	// The sources are merged in order over the values of ValuesYaml and SecretValues,
+	// values of later sources overriding those of earlier ones.
+	// +kubebuilder:validation:Optional
+	ValuesFrom []InstanceHelmChartValuesReference `json:"valuesFrom,omitempty"`
+
+	// Timeout of helm install, upgrade, rollback and uninstall operations, e.g. "10m".
+	// An integer is interpreted as nanoseconds, as with the timeout of the embedded chart spec.
+	// Defaults to 5 minutes.
+	// +kubebuilder:validation:Optional
+	Timeout *HelmTimeout `json:"timeout,omitempty"`
+
+	// Wait indicates whether helm operations wait for the resources of the release to become ready.
+	// Defaults to true.
+	// +kubebuilder:validation:Optional
+	Wait *bool `json:"wait,omitempty"`
+
+	// RollbackOnFailure indicates whether a failed install or upgrade is rolled back to the previous revision.
+	// Defaults to true. Has no effect if Atomic is set, in which case helm performs the rollback itself.
+	// +kubebuilder:validation:Optional
+	RollbackOnFailure *bool `json:"rollbackOnFailure,omitempty"`
+
+	// Patches lists Kustomize patches applied in order to the manifests rendered by the helm chart,
+	// e.g. to set fields the chart doesn't expose.
+	// +kubebuilder:validation:Optional
+	Patches []InstanceHelmChartPatch `json:"patches,omitempty"`
+}
+
+// HelmTimeout is the timeout of helm operations.
+// It is given as a duration string, e.g. "10m", or as an integer number of nanoseconds.
+// +kubebuilder:validation:XIntOrString
+type HelmTimeout struct {
+	time.Duration `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting both duration strings and integers.
+func (t *HelmTimeout) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		duration, err := time.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+
+		t.Duration = duration
+
+		return nil
+	}
+
+	var nanoseconds int64
+	if err := json.Unmarshal(b, &nanoseconds); err != nil {
+		return fmt.Errorf("helm timeout must be a duration string or an integer: %w", err)
+	}
+
+	t.Duration = time.Duration(nanoseconds)
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the timeout as a duration string.
+func (t HelmTimeout) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Duration.String())
+}
+
+// InstanceHelmChartPatch is a strategic merge or JSON6902 patch applied to the rendered manifests
+// of the helm chart. The patch is either given inline or read from a ConfigMap.
+type InstanceHelmChartPatch struct {
+	// Patch is the inline content of the patch.
+	// +kubebuilder:validation:Optional
+	Patch string `json:"patch,omitempty"`
+
+	// ConfigMapKeyRef selects the key of a ConfigMap holding the patch, in the namespace of the instance.
+	// +kubebuilder:validation:Optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// Target selects the manifests the patch is applied to. Required for JSON6902 patches.
+	// Strategic merge patches are applied to the manifest matching their kind and name if unset.
+	// +kubebuilder:validation:Optional
+	Target *InstanceHelmChartPatchTarget `json:"target,omitempty"`
+}
+
+// InstanceHelmChartPatchTarget selects the rendered manifests a patch is applied to.
+// Unset fields match any manifest.
+type InstanceHelmChartPatchTarget struct {
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the manifests, may be a regular expression.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// LabelSelector matches the labels of the manifests, e.g. "app=harbor,component=core".
+	// +kubebuilder:validation:Optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// AnnotationSelector matches the annotations of the manifests.
+	// +kubebuilder:validation:Optional
+	AnnotationSelector string `json:"annotationSelector,omitempty"`
 }
 
 type InstanceHelmChartSecretValues struct {
@@ -93,12 +587,238 @@ type InstanceHelmChartSecretValues struct {
 	Key       string                       `json:"key"`
 }
 
+// InstanceHelmChartValuesReference references a ConfigMap or Secret holding chart values.
+type InstanceHelmChartValuesReference struct {
+	// Kind of the values source.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// Name of the values source, in the namespace of the instance.
+	Name string `json:"name"`
+
+	// Key of the values source holding the values. Defaults to "values.yaml".
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+
+	// TargetPath is the dot-separated path of a single chart value, e.g. "database.external.password".
+	// If set, the content of the key is injected as a string at this path.
+	// Otherwise, the key is expected to hold a YAML document of chart values.
+	// +kubebuilder:validation:Optional
+	TargetPath string `json:"targetPath,omitempty"`
+
+	// Optional tolerates missing values sources and keys.
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
 // InstanceStatus defines the observed state of Instance.
 type InstanceStatus struct {
 	Phase InstanceStatusPhase `json:"phase"`
 
 	// +optional
 	SpecHash string `json:"specHash"`
+
+	// FailureCount is the number of consecutive failed operations,
+	// e.g. helm installs, upgrades or uninstalls, of the instance.
+	// Failed operations are retried using an exponential backoff.
+	// +optional
+	FailureCount int32 `json:"failureCount,omitempty"`
+
+	// LastAttempt is the time of the last attempted helm operation.
+	// +optional
+	LastAttempt *metav1.Time `json:"lastAttempt,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the instance's state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// RejectedConfiguration lists the items of the system configuration that are unsupported
+	// or have been rejected by Harbor.
+	// +optional
+	RejectedConfiguration []InstanceRejectedConfiguration `json:"rejectedConfiguration,omitempty"`
+
+	// OIDCConfigurationHash is the hash of the OIDC settings that have been applied to Harbor last.
+	// The client secret is only represented by the resource version of the secret holding it.
+	// +optional
+	OIDCConfigurationHash string `json:"oidcConfigurationHash,omitempty"`
+
+	// LDAPConfigurationHash is the hash of the LDAP settings that have been applied to Harbor last.
+	// The bind password is only represented by the resource version of the secret holding it.
+	// +optional
+	LDAPConfigurationHash string `json:"ldapConfigurationHash,omitempty"`
+
+	// CVEAllowlist describes the state of the system CVE allowlist, including the items that have expired.
+	// +optional
+	CVEAllowlist *CVEAllowlistStatus `json:"cveAllowlist,omitempty"`
+
+	// ScanAll describes the latest execution of the scan all job.
+	// +optional
+	ScanAll *InstanceScanAllStatus `json:"scanAll,omitempty"`
+
+	// GarbageCollection describes the latest executions of the garbage collection job.
+	// +optional
+	GarbageCollection *InstanceGarbageCollectionStatus `json:"garbageCollection,omitempty"`
+
+	// HelmRelease describes the helm release applied by the operator.
+	// +optional
+	HelmRelease *InstanceHelmReleaseStatus `json:"helmRelease,omitempty"`
+
+	// HelmReleaseRecovery describes the last recovery of the helm release from a pending state.
+	// +optional
+	HelmReleaseRecovery *InstanceHelmReleaseRecoveryStatus `json:"helmReleaseRecovery,omitempty"`
+
+	// Components holds the health of the individual Harbor components,
+	// as reported by the Harbor API during the last health check.
+	// +optional
+	Components []InstanceComponentStatus `json:"components,omitempty"`
+}
+
+// InstanceHelmReleaseStatus describes the helm release of an instance.
+type InstanceHelmReleaseStatus struct {
+	// Name of the helm release.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace the helm release is installed to.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Revision of the helm release applied by the operator last.
+	// +optional
+	Revision int `json:"revision,omitempty"`
+
+	// Chart is the name of the deployed helm chart.
+	// +optional
+	Chart string `json:"chart,omitempty"`
+
+	// ChartVersion is the version of the deployed helm chart.
+	// +optional
+	ChartVersion string `json:"chartVersion,omitempty"`
+
+	// AppVersion is the version of Harbor shipped with the deployed helm chart.
+	// +optional
+	AppVersion string `json:"appVersion,omitempty"`
+
+	// LastDeployed is the time the helm release was deployed last.
+	// +optional
+	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+
+	// Status of the helm release, e.g. "deployed", "failed" or "pending-upgrade".
+	// +optional
+	Status string `json:"status,omitempty"`
+}
+
+// InstanceHelmReleaseRecoveryStatus describes the recovery of a helm release stuck in a pending state,
+// e.g. after the operator was restarted during an upgrade.
+type InstanceHelmReleaseRecoveryStatus struct {
+	// Action taken to recover the helm release.
+	Action HelmReleaseRecoveryAction `json:"action"`
+
+	// Revision of the helm release that was stuck.
+	Revision int `json:"revision"`
+
+	// Status the stuck helm release was in, e.g. "pending-upgrade".
+	Status string `json:"status"`
+
+	// Time the helm release was recovered.
+	Time metav1.Time `json:"time"`
+
+	// Message describes the recovery.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// InstanceComponentStatus describes the health of a single Harbor component, e.g. "core" or "registry".
+type InstanceComponentStatus struct {
+	Name string `json:"name"`
+
+	// The health status of the component, either "healthy" or "unhealthy".
+	Status string `json:"status"`
+
+	// The error message reported for an unhealthy component.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Time of the health check the status was obtained with.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// InstanceGarbageCollectionStatus describes the latest executions of the garbage collection job,
+// as reported by the Harbor API.
+type InstanceGarbageCollectionStatus struct {
+	// Executions lists the latest executions of the garbage collection job, the most recent first.
+	// +optional
+	Executions []GarbageCollectionExecution `json:"executions,omitempty"`
+
+	// ReclaimableSpace is the storage space estimated to be reclaimable by the latest successful dry run.
+	// +optional
+	ReclaimableSpace *resource.Quantity `json:"reclaimableSpace,omitempty"`
+}
+
+// GarbageCollectionExecution describes a single execution of the garbage collection job.
+type GarbageCollectionExecution struct {
+	// The ID of the execution in Harbor.
+	ID int64 `json:"id"`
+
+	// The status of the execution, e.g. "Pending", "Running", "Success", "Error" or "Stopped".
+	Status string `json:"status"`
+
+	// Time the execution has been created at.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time the execution has finished at, unset for executions which are still pending or running.
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// Whether the execution has been a dry run.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// The storage space freed by the execution, as reported in the job log of successful executions.
+	// For dry runs, this is the storage space estimated to be reclaimable.
+	// +optional
+	FreedSpace *resource.Quantity `json:"freedSpace,omitempty"`
+}
+
+// InstanceScanAllStatus describes the latest execution of the scan all job, as reported by the Harbor API.
+type InstanceScanAllStatus struct {
+	// Whether the scan all job is still running.
+	// +optional
+	Ongoing bool `json:"ongoing,omitempty"`
+
+	// The trigger of the scan all job, one of "Manual", "Schedule" or "Event".
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+
+	// The total number of scans triggered by the scan all job.
+	// +optional
+	Total int64 `json:"total,omitempty"`
+
+	// The number of finished scans triggered by the scan all job.
+	// +optional
+	Completed int64 `json:"completed,omitempty"`
+
+	// The number of scans per status, e.g. "Success" or "Error".
+	// +optional
+	Metrics map[string]int64 `json:"metrics,omitempty"`
+
+	// Time the status was obtained.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// InstanceRejectedConfiguration describes a system configuration item that could not be applied to Harbor.
+type InstanceRejectedConfiguration struct {
+	Key string `json:"key"`
+
+	// The reason the configuration item has been rejected.
+	Reason string `json:"reason"`
 }
 
 type InstanceStatusPhase struct {
@@ -52,6 +52,12 @@ type ProjectSpec struct {
 	// Ref to the name of a 'User' resource
 	// +kubebuilder:validation:Optional
 	MemberRequests []MemberRequest `json:"memberRequests,omitempty"`
+
+	// CVEAllowlist is the CVE allowlist of the project, which is ignored by Harbor while
+	// metadata.reuseSysCVEAllowlist is set. Once set, the allowlist of the project is enforced by the operator.
+	// Expired items are removed from it.
+	// +kubebuilder:validation:Optional
+	CVEAllowlist *CVEAllowlist `json:"cveAllowlist,omitempty"`
 }
 
 // ProxyCacheSettings defines settings for the registry endpoint used by a "Proxy Cache" project.
@@ -125,6 +131,9 @@ type ProjectStatus struct {
 	ID int32 `json:"id,omitempty"`
 	// Members is the list of existing project member users as LocalObjectReference
 	Members []corev1.LocalObjectReference `json:"members,omitempty"`
+	// CVEAllowlist describes the state of the CVE allowlist of the project, including the items that have expired.
+	// +kubebuilder:validation:Optional
+	CVEAllowlist *CVEAllowlistStatus `json:"cveAllowlist,omitempty"`
 }
 
 func init() {
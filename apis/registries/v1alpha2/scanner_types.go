@@ -0,0 +1,159 @@
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type (
+	ScannerStatusPhaseName string
+	ScannerAuthType        string
+)
+
+const (
+	ScannerStatusPhaseUnknown     ScannerStatusPhaseName = ""
+	ScannerStatusPhaseCreating    ScannerStatusPhaseName = "Creating"
+	ScannerStatusPhaseReady       ScannerStatusPhaseName = "Ready"
+	ScannerStatusPhaseTerminating ScannerStatusPhaseName = "Terminating"
+)
+
+const (
+	ScannerAuthTypeBasic  ScannerAuthType = "Basic"
+	ScannerAuthTypeBearer ScannerAuthType = "Bearer"
+	ScannerAuthTypeAPIKey ScannerAuthType = "X-ScannerAdapter-API-Key"
+)
+
+// ScannerSpec defines the desired state of a Scanner.
+type ScannerSpec struct {
+	// Name of the scanner registration in Harbor.
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	Description string `json:"description,omitempty"`
+
+	// Base URL of the scanner adapter, e.g. "http://harbor-scanner-trivy:8080".
+	URL string `json:"url"`
+
+	// Auth configures the authentication of Harbor against the scanner adapter.
+	// +kubebuilder:validation:Optional
+	Auth *ScannerAuth `json:"auth,omitempty"`
+
+	// Whether the TLS certificate of the scanner adapter will be verified or not
+	// +kubebuilder:validation:Optional
+	SkipCertVerify bool `json:"skipCertVerify,omitempty"`
+
+	// Whether the scanner adapter pulls artifacts via the internal address of the Harbor registry or not
+	// +kubebuilder:validation:Optional
+	UseInternalAddr bool `json:"useInternalAddr,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Default marks the scanner as the system default scanner.
+	// Harbor always has a default scanner, so unsetting this does not unmark the scanner in Harbor,
+	// another scanner has to be marked as the default instead.
+	// +kubebuilder:validation:Optional
+	Default bool `json:"default,omitempty"`
+
+	// ParentInstance is a LocalObjectReference to the
+	// name of the harbor instance the scanner is registered at
+	ParentInstance corev1.LocalObjectReference `json:"parentInstance"`
+}
+
+// ScannerAuth holds the authentication settings of a scanner adapter.
+type ScannerAuth struct {
+	// Type of the authentication, sent as the scheme of the HTTP Authorization header.
+	// +kubebuilder:validation:Enum=Basic;Bearer;X-ScannerAdapter-API-Key
+	Type ScannerAuthType `json:"type"`
+
+	// AccessCredentialRef references the secret key holding the credential, e.g. "username:password"
+	// for "Basic" authentication or the token for "Bearer" authentication.
+	AccessCredentialRef corev1.SecretKeySelector `json:"accessCredentialRef"`
+}
+
+// ScannerStatus defines the observed state of Scanner.
+type ScannerStatus struct {
+	Phase   ScannerStatusPhaseName `json:"phase"`
+	Message string                 `json:"message"`
+
+	// Time of last observed transition into this state
+	// +kubebuilder:validation:Optional
+	LastTransition *metav1.Time `json:"lastTransition,omitempty"`
+
+	// The UUID of the scanner registration is written back from the held registration.
+	// +kubebuilder:validation:Optional
+	UUID string `json:"uuid,omitempty"`
+
+	// Health of the scanner adapter, as reported by Harbor.
+	// +kubebuilder:validation:Optional
+	Health string `json:"health,omitempty"`
+
+	// Whether the scanner is the system default scanner.
+	// +kubebuilder:validation:Optional
+	Default bool `json:"default,omitempty"`
+
+	// Metadata of the scanner adapter, as reported by the adapter.
+	// +kubebuilder:validation:Optional
+	Metadata *ScannerMetadata `json:"metadata,omitempty"`
+
+	// SpecHash is the hash of the registration that has been applied to Harbor last.
+	// The access credential is only represented by the resource version of the secret holding it.
+	// +kubebuilder:validation:Optional
+	SpecHash string `json:"specHash,omitempty"`
+}
+
+// ScannerMetadata describes a scanner adapter and its capabilities.
+type ScannerMetadata struct {
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Vendor string `json:"vendor,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Capabilities []ScannerCapability `json:"capabilities,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ScannerCapability describes the artifacts a scanner adapter is able to scan, and the reports it produces.
+type ScannerCapability struct {
+	// +kubebuilder:validation:Optional
+	ConsumesMimeTypes []string `json:"consumesMimeTypes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ProducesMimeTypes []string `json:"producesMimeTypes,omitempty"`
+}
+
+// Scanner is the Schema for the scanners API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=scanners,scope=Namespaced
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="phase"
+// +kubebuilder:printcolumn:name="Default",type="boolean",JSONPath=".status.default",description="system default scanner"
+// +kubebuilder:printcolumn:name="Health",type="string",JSONPath=".status.health",description="scanner health"
+// +kubebuilder:object:root=true
+
+type Scanner struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScannerSpec `json:"spec,omitempty"`
+
+	Status ScannerStatus `json:"status,omitempty"`
+}
+
+// ScannerList contains a list of Scanner
+// +kubebuilder:object:root=true
+type ScannerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Scanner `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Scanner{}, &ScannerList{})
+}
@@ -22,12 +22,112 @@ package v1alpha2
 
 import (
 	"k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogPurge) DeepCopyInto(out *AuditLogPurge) {
+	*out = *in
+	if in.IncludeOperations != nil {
+		in, out := &in.IncludeOperations, &out.IncludeOperations
+		*out = make([]AuditLogOperation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogPurge.
+func (in *AuditLogPurge) DeepCopy() *AuditLogPurge {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogPurge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CVEAllowlist) DeepCopyInto(out *CVEAllowlist) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CVEAllowlistItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CVEAllowlist.
+func (in *CVEAllowlist) DeepCopy() *CVEAllowlist {
+	if in == nil {
+		return nil
+	}
+	out := new(CVEAllowlist)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CVEAllowlistItem) DeepCopyInto(out *CVEAllowlistItem) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CVEAllowlistItem.
+func (in *CVEAllowlistItem) DeepCopy() *CVEAllowlistItem {
+	if in == nil {
+		return nil
+	}
+	out := new(CVEAllowlistItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CVEAllowlistStatus) DeepCopyInto(out *CVEAllowlistStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Expired != nil {
+		in, out := &in.Expired, &out.Expired
+		*out = make([]CVEAllowlistItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CVEAllowlistStatus.
+func (in *CVEAllowlistStatus) DeepCopy() *CVEAllowlistStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CVEAllowlistStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GarbageCollection) DeepCopyInto(out *GarbageCollection) {
 	*out = *in
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollection.
@@ -40,6 +140,49 @@ func (in *GarbageCollection) DeepCopy() *GarbageCollection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectionExecution) DeepCopyInto(out *GarbageCollectionExecution) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FreedSpace != nil {
+		in, out := &in.FreedSpace, &out.FreedSpace
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectionExecution.
+func (in *GarbageCollectionExecution) DeepCopy() *GarbageCollectionExecution {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectionExecution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmTimeout) DeepCopyInto(out *HelmTimeout) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmTimeout.
+func (in *HelmTimeout) DeepCopy() *HelmTimeout {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmTimeout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Instance) DeepCopyInto(out *Instance) {
 	*out = *in
@@ -67,6 +210,76 @@ func (in *Instance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceAPIClientSpec) DeepCopyInto(out *InstanceAPIClientSpec) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(InstanceCABundleSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceAPIClientSpec.
+func (in *InstanceAPIClientSpec) DeepCopy() *InstanceAPIClientSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceAPIClientSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceAdminCredentials) DeepCopyInto(out *InstanceAdminCredentials) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceAdminCredentials.
+func (in *InstanceAdminCredentials) DeepCopy() *InstanceAdminCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceAdminCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceCABundleSource) DeepCopyInto(out *InstanceCABundleSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceCABundleSource.
+func (in *InstanceCABundleSource) DeepCopy() *InstanceCABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceCABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceChartRepository) DeepCopyInto(out *InstanceChartRepository) {
 	*out = *in
@@ -161,6 +374,89 @@ func (in *InstanceChartRepositoryStatus) DeepCopy() *InstanceChartRepositoryStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceComponentStatus) DeepCopyInto(out *InstanceComponentStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceComponentStatus.
+func (in *InstanceComponentStatus) DeepCopy() *InstanceComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceGarbageCollectionStatus) DeepCopyInto(out *InstanceGarbageCollectionStatus) {
+	*out = *in
+	if in.Executions != nil {
+		in, out := &in.Executions, &out.Executions
+		*out = make([]GarbageCollectionExecution, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReclaimableSpace != nil {
+		in, out := &in.ReclaimableSpace, &out.ReclaimableSpace
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceGarbageCollectionStatus.
+func (in *InstanceGarbageCollectionStatus) DeepCopy() *InstanceGarbageCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceGarbageCollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHelmChartPatch) DeepCopyInto(out *InstanceHelmChartPatch) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(InstanceHelmChartPatchTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmChartPatch.
+func (in *InstanceHelmChartPatch) DeepCopy() *InstanceHelmChartPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHelmChartPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHelmChartPatchTarget) DeepCopyInto(out *InstanceHelmChartPatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmChartPatchTarget.
+func (in *InstanceHelmChartPatchTarget) DeepCopy() *InstanceHelmChartPatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHelmChartPatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceHelmChartSecretValues) DeepCopyInto(out *InstanceHelmChartSecretValues) {
 	*out = *in
@@ -190,6 +486,33 @@ func (in *InstanceHelmChartSpec) DeepCopyInto(out *InstanceHelmChartSpec) {
 		*out = new(InstanceHelmChartSecretValues)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]InstanceHelmChartValuesReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(HelmTimeout)
+		**out = **in
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RollbackOnFailure != nil {
+		in, out := &in.RollbackOnFailure, &out.RollbackOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]InstanceHelmChartPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmChartSpec.
@@ -202,6 +525,81 @@ func (in *InstanceHelmChartSpec) DeepCopy() *InstanceHelmChartSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHelmChartValuesReference) DeepCopyInto(out *InstanceHelmChartValuesReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmChartValuesReference.
+func (in *InstanceHelmChartValuesReference) DeepCopy() *InstanceHelmChartValuesReference {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHelmChartValuesReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHelmReleaseRecoveryStatus) DeepCopyInto(out *InstanceHelmReleaseRecoveryStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmReleaseRecoveryStatus.
+func (in *InstanceHelmReleaseRecoveryStatus) DeepCopy() *InstanceHelmReleaseRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHelmReleaseRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHelmReleaseStatus) DeepCopyInto(out *InstanceHelmReleaseStatus) {
+	*out = *in
+	if in.LastDeployed != nil {
+		in, out := &in.LastDeployed, &out.LastDeployed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHelmReleaseStatus.
+func (in *InstanceHelmReleaseStatus) DeepCopy() *InstanceHelmReleaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHelmReleaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceLDAPSpec) DeepCopyInto(out *InstanceLDAPSpec) {
+	*out = *in
+	if in.BindPasswordRef != nil {
+		in, out := &in.BindPasswordRef, &out.BindPasswordRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VerifyCert != nil {
+		in, out := &in.VerifyCert, &out.VerifyCert
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceLDAPSpec.
+func (in *InstanceLDAPSpec) DeepCopy() *InstanceLDAPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceLDAPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceList) DeepCopyInto(out *InstanceList) {
 	*out = *in
@@ -234,6 +632,77 @@ func (in *InstanceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceOIDCSpec) DeepCopyInto(out *InstanceOIDCSpec) {
+	*out = *in
+	in.ClientSecretRef.DeepCopyInto(&out.ClientSecretRef)
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerifyCert != nil {
+		in, out := &in.VerifyCert, &out.VerifyCert
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExtraRedirectParams != nil {
+		in, out := &in.ExtraRedirectParams, &out.ExtraRedirectParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceOIDCSpec.
+func (in *InstanceOIDCSpec) DeepCopy() *InstanceOIDCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceOIDCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceRejectedConfiguration) DeepCopyInto(out *InstanceRejectedConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceRejectedConfiguration.
+func (in *InstanceRejectedConfiguration) DeepCopy() *InstanceRejectedConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceRejectedConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceScanAllStatus) DeepCopyInto(out *InstanceScanAllStatus) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceScanAllStatus.
+func (in *InstanceScanAllStatus) DeepCopy() *InstanceScanAllStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceScanAllStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 	*out = *in
@@ -242,11 +711,58 @@ func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 		*out = new(InstanceHelmChartSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdminCredentials != nil {
+		in, out := &in.AdminCredentials, &out.AdminCredentials
+		*out = new(InstanceAdminCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIClient != nil {
+		in, out := &in.APIClient, &out.APIClient
+		*out = new(InstanceAPIClientSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessTimeout != nil {
+		in, out := &in.ReadinessTimeout, &out.ReadinessTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.GarbageCollection != nil {
 		in, out := &in.GarbageCollection, &out.GarbageCollection
 		*out = new(GarbageCollection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScanAll != nil {
+		in, out := &in.ScanAll, &out.ScanAll
+		*out = new(ScanAll)
 		**out = **in
 	}
+	if in.AuditLogPurge != nil {
+		in, out := &in.AuditLogPurge, &out.AuditLogPurge
+		*out = new(AuditLogPurge)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(InstanceOIDCSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(InstanceLDAPSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CVEAllowlist != nil {
+		in, out := &in.CVEAllowlist, &out.CVEAllowlist
+		*out = new(CVEAllowlist)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSpec.
@@ -263,6 +779,54 @@ func (in *InstanceSpec) DeepCopy() *InstanceSpec {
 func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 	*out = *in
 	in.Phase.DeepCopyInto(&out.Phase)
+	if in.LastAttempt != nil {
+		in, out := &in.LastAttempt, &out.LastAttempt
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RejectedConfiguration != nil {
+		in, out := &in.RejectedConfiguration, &out.RejectedConfiguration
+		*out = make([]InstanceRejectedConfiguration, len(*in))
+		copy(*out, *in)
+	}
+	if in.CVEAllowlist != nil {
+		in, out := &in.CVEAllowlist, &out.CVEAllowlist
+		*out = new(CVEAllowlistStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScanAll != nil {
+		in, out := &in.ScanAll, &out.ScanAll
+		*out = new(InstanceScanAllStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(InstanceGarbageCollectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HelmRelease != nil {
+		in, out := &in.HelmRelease, &out.HelmRelease
+		*out = new(InstanceHelmReleaseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HelmReleaseRecovery != nil {
+		in, out := &in.HelmReleaseRecovery, &out.HelmReleaseRecovery
+		*out = new(InstanceHelmReleaseRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]InstanceComponentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceStatus.
@@ -404,6 +968,11 @@ func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 		*out = make([]MemberRequest, len(*in))
 		copy(*out, *in)
 	}
+	if in.CVEAllowlist != nil {
+		in, out := &in.CVEAllowlist, &out.CVEAllowlist
+		*out = new(CVEAllowlist)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSpec.
@@ -428,6 +997,11 @@ func (in *ProjectStatus) DeepCopyInto(out *ProjectStatus) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.CVEAllowlist != nil {
+		in, out := &in.CVEAllowlist, &out.CVEAllowlist
+		*out = new(CVEAllowlistStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectStatus.
@@ -720,6 +1294,195 @@ func (in *ReplicationTrigger) DeepCopy() *ReplicationTrigger {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanAll) DeepCopyInto(out *ScanAll) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanAll.
+func (in *ScanAll) DeepCopy() *ScanAll {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanAll)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scanner) DeepCopyInto(out *Scanner) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scanner.
+func (in *Scanner) DeepCopy() *Scanner {
+	if in == nil {
+		return nil
+	}
+	out := new(Scanner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Scanner) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerAuth) DeepCopyInto(out *ScannerAuth) {
+	*out = *in
+	in.AccessCredentialRef.DeepCopyInto(&out.AccessCredentialRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerAuth.
+func (in *ScannerAuth) DeepCopy() *ScannerAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerCapability) DeepCopyInto(out *ScannerCapability) {
+	*out = *in
+	if in.ConsumesMimeTypes != nil {
+		in, out := &in.ConsumesMimeTypes, &out.ConsumesMimeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProducesMimeTypes != nil {
+		in, out := &in.ProducesMimeTypes, &out.ProducesMimeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerCapability.
+func (in *ScannerCapability) DeepCopy() *ScannerCapability {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerCapability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerList) DeepCopyInto(out *ScannerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Scanner, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerList.
+func (in *ScannerList) DeepCopy() *ScannerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScannerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerMetadata) DeepCopyInto(out *ScannerMetadata) {
+	*out = *in
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]ScannerCapability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerMetadata.
+func (in *ScannerMetadata) DeepCopy() *ScannerMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerSpec) DeepCopyInto(out *ScannerSpec) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ScannerAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	out.ParentInstance = in.ParentInstance
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerSpec.
+func (in *ScannerSpec) DeepCopy() *ScannerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScannerStatus) DeepCopyInto(out *ScannerStatus) {
+	*out = *in
+	if in.LastTransition != nil {
+		in, out := &in.LastTransition, &out.LastTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ScannerMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScannerStatus.
+func (in *ScannerStatus) DeepCopy() *ScannerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScannerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerSettings) DeepCopyInto(out *TriggerSettings) {
 	*out = *in
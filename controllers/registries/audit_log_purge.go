@@ -0,0 +1,73 @@
+package registries
+
+import (
+	"context"
+	"errors"
+
+	clienterrors "github.com/mittwald/goharbor-client/v5/apiv2/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileAuditLogPurge syncs the audit log purge schedule of an instance
+// and reflects the result in the "AuditLogPurgeSynced" condition of the instance.
+func (r *InstanceReconciler) reconcileAuditLogPurge(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if harbor.Spec.AuditLogPurge == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionAuditLogPurgeSynced)
+		return nil
+	}
+
+	if err := r.syncAuditLogPurgeSchedule(ctx, harbor); err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionAuditLogPurgeSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonScheduleSyncFailed, err.Error())
+		return err
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionAuditLogPurgeSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonScheduleSynced, "audit log purge schedule is up to date")
+
+	return nil
+}
+
+// syncAuditLogPurgeSchedule reads the state of a configured audit log purge schedule and compares it to the user
+// defined audit log purge schedule.
+func (r *InstanceReconciler) syncAuditLogPurgeSchedule(ctx context.Context, harbor *v1alpha2.Instance) error {
+	scheduleType, err := enumScheduleType(harbor.Spec.AuditLogPurge.ScheduleType)
+	if err != nil {
+		return err
+	}
+
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return err
+	}
+
+	newPurge := internal.ToAuditLogPurgeSchedule(harbor.Spec.AuditLogPurge, scheduleType)
+
+	purge, err := harborClient.GetPurgeSchedule(ctx)
+
+	// The purge client reports a missing schedule as an unknown resource.
+	var notFound *clienterrors.ErrQuotaUnknownResource
+	if err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+
+	if err != nil || purge == nil || purge.Schedule == nil {
+		// The initial purge schedule is undefined, set it to the desired schedule.
+		if scheduleType == v1alpha2.ScheduleTypeNone {
+			return nil
+		}
+
+		return harborClient.CreatePurgeSchedule(ctx, newPurge)
+	}
+
+	// Compare the constructed purge schedule to the existing one and update accordingly
+	if !internal.AuditLogPurgeScheduleMatches(purge, newPurge) {
+		return harborClient.UpdatePurgeSchedule(ctx, newPurge)
+	}
+
+	return nil
+}
@@ -1,12 +1,21 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 const (
 	FlagMetricsAddress          string = "metrics-addr"
 	FlagEnableLeaderElection    string = "enable-leader-election"
 	FlagHelmClientRepoCachePath string = "helm-client-repo-cache-path"
 	FlagHelmClientRepoConfPath  string = "helm-client-repo-conf-path"
+	FlagHealthCheckInterval     string = "health-check-interval"
+	FlagPendingReleaseTimeout   string = "pending-release-timeout"
+
+	DefaultHealthCheckInterval   = 1 * time.Minute
+	DefaultPendingReleaseTimeout = 15 * time.Minute
 )
 
 var (
@@ -22,4 +31,6 @@ func FromViper() {
 	Config.HelmClientRepositoryConfigPath = viper.GetString("helm-client-repo-conf-path")
 	Config.MetricsAddr = viper.GetString("metrics-addr")
 	Config.EnableLeaderElection = viper.GetBool("enable-leader-election")
+	Config.HealthCheckInterval = viper.GetDuration("health-check-interval")
+	Config.PendingReleaseTimeout = viper.GetDuration("pending-release-timeout")
 }
@@ -1,8 +1,12 @@
 package config
 
+import "time"
+
 type config struct {
-	HelmClientRepositoryCachePath  string `default:"/tmp/.helmcache" split_words:"true"`
-	HelmClientRepositoryConfigPath string `default:"/tmp/.helmrepo" split_words:"true"`
-	MetricsAddr                    string `default:":8080"`
-	EnableLeaderElection           bool   `default:"true"`
+	HelmClientRepositoryCachePath  string        `default:"/tmp/.helmcache" split_words:"true"`
+	HelmClientRepositoryConfigPath string        `default:"/tmp/.helmrepo" split_words:"true"`
+	MetricsAddr                    string        `default:":8080"`
+	EnableLeaderElection           bool          `default:"true"`
+	HealthCheckInterval            time.Duration `default:"1m" split_words:"true"`
+	PendingReleaseTimeout          time.Duration `default:"15m" split_words:"true"`
 }
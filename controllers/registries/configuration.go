@@ -0,0 +1,217 @@
+package registries
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileConfiguration syncs the system configuration of an instance, reporting configuration items that are
+// unsupported or have been rejected by Harbor in the instance status.
+// The result is reflected in the "ConfigurationSynced" condition of the instance.
+func (r *InstanceReconciler) reconcileConfiguration(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if len(harbor.Spec.Configuration) == 0 {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionConfigurationSynced)
+		harbor.Status.RejectedConfiguration = nil
+
+		return nil
+	}
+
+	rejected, err := r.syncConfiguration(ctx, harbor)
+	if err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionConfigurationSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonConfigurationSyncFailed, err.Error())
+		return err
+	}
+
+	harbor.Status.RejectedConfiguration = rejected
+
+	if len(rejected) > 0 {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionConfigurationSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonConfigurationRejected,
+			fmt.Sprintf("%d configuration item(s) have been rejected, see .status.rejectedConfiguration", len(rejected)))
+		return nil
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionConfigurationSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonConfigurationSynced, "system configuration is up to date")
+
+	return nil
+}
+
+// syncConfiguration compares the system configuration of an instance to the user defined configuration
+// and updates the differing items. Returns the configuration items which could not be applied.
+func (r *InstanceReconciler) syncConfiguration(ctx context.Context,
+	harbor *v1alpha2.Instance) ([]v1alpha2.InstanceRejectedConfiguration, error) {
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, managed := filterManagedConfiguration(harbor)
+
+	current, err := harborClient.GetConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	update, rejected, err := internal.DiffConfiguration(desired, current)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(update) == 0 {
+		return sortRejectedConfiguration(append(rejected, managed...)), nil
+	}
+
+	cfg, err := internal.ToConfigurations(update)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := harborClient.UpdateConfigs(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	// Harbor may refuse to apply some items without reporting an error,
+	// e.g. changes of the auth mode once users have been created. Those are detected by comparing again.
+	current, err = harborClient.GetConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notApplied, rejected, err := internal.DiffConfiguration(desired, current)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range notApplied {
+		rejected = append(rejected, v1alpha2.InstanceRejectedConfiguration{
+			Key:    key,
+			Reason: "configuration item has not been applied by harbor",
+		})
+	}
+
+	return sortRejectedConfiguration(append(rejected, managed...)), nil
+}
+
+// filterManagedConfiguration returns the system configuration items of an instance which are not managed via
+// dedicated fields of the instance spec, rejecting the remaining ones.
+func filterManagedConfiguration(
+	harbor *v1alpha2.Instance) (map[string]apiextensionsv1.JSON, []v1alpha2.InstanceRejectedConfiguration) {
+	desired := make(map[string]apiextensionsv1.JSON, len(harbor.Spec.Configuration))
+
+	var managed []v1alpha2.InstanceRejectedConfiguration
+
+	for key, value := range harbor.Spec.Configuration {
+		if harbor.Spec.OIDC != nil && (key == "auth_mode" || strings.HasPrefix(key, "oidc_")) {
+			managed = append(managed, v1alpha2.InstanceRejectedConfiguration{
+				Key:    key,
+				Reason: "configuration item is managed via .spec.oidc",
+			})
+
+			continue
+		}
+
+		if harbor.Spec.LDAP != nil && (key == "auth_mode" || strings.HasPrefix(key, "ldap_")) {
+			managed = append(managed, v1alpha2.InstanceRejectedConfiguration{
+				Key:    key,
+				Reason: "configuration item is managed via .spec.ldap",
+			})
+
+			continue
+		}
+
+		desired[key] = value
+	}
+
+	return desired, managed
+}
+
+func sortRejectedConfiguration(
+	rejected []v1alpha2.InstanceRejectedConfiguration) []v1alpha2.InstanceRejectedConfiguration {
+	sort.Slice(rejected, func(i, j int) bool {
+		return rejected[i].Key < rejected[j].Key
+	})
+
+	return rejected
+}
+
+// applyManagedConfiguration applies system configuration items managed via dedicated fields of the instance spec,
+// e.g. the OIDC settings. All items are updated if any of them differs from the current configuration, or if their
+// hash differs from the hash of the items applied last, as write-only items like secrets cannot be read from the
+// Harbor API. The write-only items are represented in the hash by the resource version of the secret holding them.
+// Returns the hash of the applied items.
+func (r *InstanceReconciler) applyManagedConfiguration(ctx context.Context, harbor *v1alpha2.Instance,
+	cfg *model.Configurations, appliedHash, secretVersion string, writeOnlyKeys ...string) (string, error) {
+	desired, err := internal.ConfigurationValues(cfg, writeOnlyKeys...)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := internal.ConfigurationHash(desired, secretVersion)
+	if err != nil {
+		return "", err
+	}
+
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := harborClient.GetConfigs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	update, rejected, err := internal.DiffConfiguration(desired, current)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rejected) > 0 {
+		return "", fmt.Errorf("%s: %s", rejected[0].Key, rejected[0].Reason)
+	}
+
+	if len(update) == 0 && appliedHash == hash {
+		return appliedHash, nil
+	}
+
+	if err := harborClient.UpdateConfigs(ctx, cfg); err != nil {
+		return "", err
+	}
+
+	// Harbor refuses to apply some items without reporting an error,
+	// e.g. changes of the auth mode once users have been created.
+	current, err = harborClient.GetConfigs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	notApplied, _, err := internal.DiffConfiguration(desired, current)
+	if err != nil {
+		return "", err
+	}
+
+	if len(notApplied) > 0 {
+		keys := make([]string, 0, len(notApplied))
+		for key := range notApplied {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		return "", fmt.Errorf("configuration items %q have not been applied by harbor", keys)
+	}
+
+	return hash, nil
+}
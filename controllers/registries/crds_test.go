@@ -57,6 +57,13 @@ var _ = Describe("CRDInstallation", func() {
 						Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1alpha2"}},
 					},
 				},
+				{
+					Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+						Group:    "registries.mittwald.de",
+						Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: "scanners"},
+						Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1alpha2"}},
+					},
+				},
 				{
 					Spec: apiextensionsv1.CustomResourceDefinitionSpec{
 						Group:    "registries.mittwald.de",
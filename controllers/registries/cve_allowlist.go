@@ -0,0 +1,74 @@
+package registries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileCVEAllowlist syncs the system CVE allowlist of an instance, removing expired items from it.
+// The result is reflected in the "CVEAllowlistSynced" condition of the instance.
+func (r *InstanceReconciler) reconcileCVEAllowlist(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if harbor.Spec.CVEAllowlist == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionCVEAllowlistSynced)
+		harbor.Status.CVEAllowlist = nil
+
+		return nil
+	}
+
+	active, expired := internal.PartitionCVEAllowlist(harbor.Spec.CVEAllowlist, metav1.Now().Time)
+
+	if err := r.syncCVEAllowlist(ctx, harbor, active); err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionCVEAllowlistSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonCVEAllowlistSyncFailed, err.Error())
+		return err
+	}
+
+	harbor.Status.CVEAllowlist = &v1alpha2.CVEAllowlistStatus{
+		Active:  active,
+		Expired: expired,
+	}
+
+	msg := "system CVE allowlist is up to date"
+	if len(expired) > 0 {
+		msg = fmt.Sprintf("system CVE allowlist is up to date, %d expired item(s) have been removed", len(expired))
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionCVEAllowlistSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonCVEAllowlistSynced, msg)
+
+	return nil
+}
+
+// syncCVEAllowlist compares the system CVE allowlist of Harbor to the given CVE IDs and updates it accordingly.
+// The allowlist itself is kept from expiring, as expiry is handled per item by the operator.
+func (r *InstanceReconciler) syncCVEAllowlist(ctx context.Context, harbor *v1alpha2.Instance, active []string) error {
+	apiClient, err := r.HarborClients.GetAPIClient(ctx, r.Client, harbor)
+	if err != nil {
+		return err
+	}
+
+	current, err := apiClient.GetSystemCVEAllowlist(ctx)
+	if err != nil {
+		return fmt.Errorf("reading system CVE allowlist failed: %w", err)
+	}
+
+	if internal.CVEAllowlistMatches(current, active) {
+		return nil
+	}
+
+	err = apiClient.UpdateSystemCVEAllowlist(ctx, &model.CVEAllowlist{
+		Items: internal.ToCVEAllowlistItems(active),
+	})
+	if err != nil {
+		return fmt.Errorf("updating system CVE allowlist failed: %w", err)
+	}
+
+	return nil
+}
@@ -4,53 +4,200 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
 
 	"github.com/mittwald/goharbor-client/v5/apiv2/model"
 	clienterrors "github.com/mittwald/goharbor-client/v5/apiv2/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
 	"github.com/mittwald/harbor-operator/controllers/registries/internal"
 )
 
-// reconcileGarbageCollection reads the state of a configured garbage collection schedule and compares it to the user
-// defined garbage collection schedule.
+const (
+	// defaultGarbageCollectionHistoryLimit is the number of garbage collection executions reported
+	// in the instance status if GarbageCollection.HistoryLimit is not set.
+	defaultGarbageCollectionHistoryLimit int32 = 5
+
+	// harborScheduleTypeManual is the schedule type used by Harbor for manually triggered jobs.
+	harborScheduleTypeManual = "Manual"
+)
+
+// reconcileGarbageCollection starts a garbage collection if requested via the "registries.mittwald.de/trigger-gc"
+// annotation, syncs the garbage collection schedule of an instance and records the latest executions of the
+// garbage collection job in the instance status.
+// The result of the sync is reflected in the "GarbageCollectionSynced" condition of the instance.
 func (r *InstanceReconciler) reconcileGarbageCollection(ctx context.Context, harbor *v1alpha2.Instance) error {
-	scheduleType, err := enumGCType(harbor.Spec.GarbageCollection.ScheduleType)
-	if err != nil {
+	if err := r.triggerGarbageCollection(ctx, harbor); err != nil {
+		return fmt.Errorf("triggering garbage collection failed: %w", err)
+	}
+
+	if harbor.Spec.GarbageCollection == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionGarbageCollectionSynced)
+		harbor.Status.GarbageCollection = nil
+
+		return nil
+	}
+
+	if err := r.syncGarbageCollectionSchedule(ctx, harbor); err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionGarbageCollectionSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonScheduleSyncFailed, err.Error())
 		return err
 	}
 
-	harborClient, err := internal.BuildClient(ctx, r.Client, harbor)
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionGarbageCollectionSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonScheduleSynced, "garbage collection schedule is up to date")
+
+	r.updateGarbageCollectionStatus(ctx, harbor)
+
+	return nil
+}
+
+// triggerGarbageCollection starts an immediate garbage collection if the instance is annotated with
+// "registries.mittwald.de/trigger-gc". The annotation is removed once the job has been accepted by Harbor.
+func (r *InstanceReconciler) triggerGarbageCollection(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if _, ok := harbor.Annotations[v1alpha2.AnnotationTriggerGarbageCollection]; !ok {
+		return nil
+	}
+
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
 	if err != nil {
 		return err
 	}
 
-	newGc := model.Schedule{
+	gc := model.Schedule{
 		Schedule: &model.ScheduleObj{
-			Cron: harbor.Spec.GarbageCollection.Cron,
-			Type: string(scheduleType),
+			Type: harborScheduleTypeManual,
 		},
+		Parameters: internal.GarbageCollectionParameters(harbor.Spec.GarbageCollection),
+	}
+
+	err = harborClient.NewGarbageCollection(ctx, &gc)
+
+	var inProgress *clienterrors.ErrSystemGcInProgress
+	if errors.As(err, &inProgress) {
+		// The annotation is kept, so that the garbage collection is triggered once the running one has finished.
+		r.Log.Info("garbage collection is already running, postponing triggered garbage collection",
+			"instance", harbor.Name)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r.Log.Info("triggered garbage collection", "instance", harbor.Name)
+
+	// The annotation is removed via a separate patch, as the status of the instance is patched on its own.
+	triggered := harbor.DeepCopy()
+	delete(triggered.Annotations, v1alpha2.AnnotationTriggerGarbageCollection)
+
+	return r.Client.Patch(ctx, triggered, client.MergeFrom(harbor))
+}
+
+// updateGarbageCollectionStatus records the latest executions of the garbage collection job in the status
+// of an instance. The previous status is kept if the executions cannot be obtained.
+func (r *InstanceReconciler) updateGarbageCollectionStatus(ctx context.Context, harbor *v1alpha2.Instance) {
+	apiClient, err := r.HarborClients.GetAPIClient(ctx, r.Client, harbor)
+	if err != nil {
+		r.Log.Error(err, "building harbor API client failed", "instance", harbor.Name)
+		return
+	}
+
+	limit := defaultGarbageCollectionHistoryLimit
+	if harbor.Spec.GarbageCollection.HistoryLimit != nil {
+		limit = *harbor.Spec.GarbageCollection.HistoryLimit
+	}
+
+	executions, err := apiClient.ListGarbageCollectionExecutions(ctx, limit)
+	if err != nil {
+		r.Log.Error(err, "listing garbage collection executions failed", "instance", harbor.Name)
+		return
+	}
+
+	// The freed space is only looked up once for each successful execution.
+	freedSpace := make(map[int64]*resource.Quantity)
+	if harbor.Status.GarbageCollection != nil {
+		for i := range harbor.Status.GarbageCollection.Executions {
+			execution := harbor.Status.GarbageCollection.Executions[i]
+			freedSpace[execution.ID] = execution.FreedSpace
+		}
+	}
+
+	status := v1alpha2.InstanceGarbageCollectionStatus{}
+
+	// The estimation of the latest successful dry run is kept once its execution is no longer listed.
+	if harbor.Status.GarbageCollection != nil {
+		status.ReclaimableSpace = harbor.Status.GarbageCollection.ReclaimableSpace
 	}
 
-	if harbor.Spec.GarbageCollection.DeleteUntagged {
-		newGc.Parameters = map[string]interface{}{
-			"delete_untagged": true,
+	var dryRunReported bool
+
+	for _, held := range executions {
+		if held == nil {
+			continue
+		}
+
+		if int32(len(status.Executions)) >= limit {
+			break
+		}
+
+		execution := internal.ToGarbageCollectionExecution(held)
+
+		if internal.IsGarbageCollectionSucceeded(held.JobStatus) {
+			execution.FreedSpace = freedSpace[held.ID]
+
+			if execution.FreedSpace == nil {
+				log, err := apiClient.GetGarbageCollectionLog(ctx, held.ID)
+				if err != nil {
+					r.Log.Error(err, "fetching garbage collection log failed", "instance", harbor.Name, "id", held.ID)
+				} else {
+					execution.FreedSpace = internal.ParseGarbageCollectionFreedSpace(log)
+				}
+			}
+		}
+
+		if execution.DryRun && execution.FreedSpace != nil && !dryRunReported {
+			status.ReclaimableSpace = execution.FreedSpace
+			dryRunReported = true
 		}
+
+		status.Executions = append(status.Executions, execution)
+	}
+
+	harbor.Status.GarbageCollection = &status
+}
+
+// syncGarbageCollectionSchedule reads the state of a configured garbage collection schedule and compares it to the user
+// defined garbage collection schedule.
+func (r *InstanceReconciler) syncGarbageCollectionSchedule(ctx context.Context, harbor *v1alpha2.Instance) error {
+	scheduleType, err := enumScheduleType(harbor.Spec.GarbageCollection.ScheduleType)
+	if err != nil {
+		return err
+	}
+
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return err
 	}
 
+	newGc := internal.ToGarbageCollectionSchedule(harbor.Spec.GarbageCollection, scheduleType)
+
 	gc, err := harborClient.GetGarbageCollectionSchedule(ctx)
 	if err != nil {
 		if errors.Is(&clienterrors.ErrSystemGcScheduleUndefined{}, err) {
 			// The initial GC schedule is always undefined, set it to the desired schedule.
-			return harborClient.NewGarbageCollection(ctx, &newGc)
+			return harborClient.NewGarbageCollection(ctx, newGc)
 		}
 		return err
 	}
 
-	// Compare the constructed garbage collection to the existing one and update accordingly
-	if !reflect.DeepEqual(newGc.Schedule, gc.Schedule) {
-		err = harborClient.UpdateGarbageCollection(ctx, &newGc)
+	// Compare the constructed garbage collection, including its parameters, to the existing one
+	// and update accordingly
+	if !internal.GarbageCollectionScheduleMatches(gc, newGc) {
+		err = harborClient.UpdateGarbageCollection(ctx, newGc)
 		if err != nil {
 			return err
 		}
@@ -59,8 +206,9 @@ func (r *InstanceReconciler) reconcileGarbageCollection(ctx context.Context, har
 	return nil
 }
 
-// enumGCType enumerates a string against valid GarbageCollection schedule types.
-func enumGCType(receivedScheduleType v1alpha2.ScheduleType) (v1alpha2.ScheduleType, error) {
+// enumScheduleType enumerates a string against valid schedule types,
+// e.g. of the garbage collection or scan all schedules.
+func enumScheduleType(receivedScheduleType v1alpha2.ScheduleType) (v1alpha2.ScheduleType, error) {
 	switch receivedScheduleType {
 	case v1alpha2.ScheduleTypeCustom, v1alpha2.ScheduleTypeDaily,
 		v1alpha2.ScheduleTypeHourly, v1alpha2.ScheduleTypeManually,
@@ -68,6 +216,6 @@ func enumGCType(receivedScheduleType v1alpha2.ScheduleType) (v1alpha2.ScheduleTy
 		return receivedScheduleType, nil
 
 	default:
-		return "", fmt.Errorf("invalid garbage collection schedule type provided: '%s'", receivedScheduleType)
+		return "", fmt.Errorf("invalid schedule type provided: '%s'", receivedScheduleType)
 	}
 }
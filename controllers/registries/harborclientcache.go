@@ -0,0 +1,165 @@
+package registries
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	h "github.com/mittwald/goharbor-client/v5/apiv2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// HarborClientCache caches harbor API clients per instance, so that they are not rebuilt on every reconciliation.
+// Cached clients are invalidated as soon as the instance spec, the secret holding the admin credentials
+// or the referenced CA bundle changes.
+// A nil cache is valid and builds a new client on every call.
+type HarborClientCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]harborClientCacheEntry
+}
+
+type harborClientCacheEntry struct {
+	key       string
+	client    *h.RESTClient
+	apiClient *internal.APIClient
+}
+
+// NewHarborClientCache returns an empty harbor client cache that is safe for concurrent use.
+func NewHarborClientCache() *HarborClientCache {
+	return &HarborClientCache{
+		entries: make(map[types.UID]harborClientCacheEntry),
+	}
+}
+
+// Get returns the cached harbor client of an instance, building a new one if the cached client is outdated.
+func (c *HarborClientCache) Get(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (*h.RESTClient, error) {
+	if c == nil {
+		return internal.BuildClient(ctx, cl, harbor)
+	}
+
+	entry, err := c.get(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.client, nil
+}
+
+// GetAPIClient returns the cached client for the harbor API endpoints not covered by the harbor client
+// of an instance, building a new one if the cached client is outdated.
+func (c *HarborClientCache) GetAPIClient(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (*internal.APIClient, error) {
+	if c == nil {
+		return internal.BuildAPIClient(ctx, cl, harbor)
+	}
+
+	entry, err := c.get(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.apiClient, nil
+}
+
+// get returns the up to date cache entry of an instance, rebuilding its clients if necessary.
+func (c *HarborClientCache) get(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (harborClientCacheEntry, error) {
+	sec, err := internal.GetAdminCredentialsSecret(ctx, cl, harbor)
+	if err != nil {
+		return harborClientCacheEntry{}, err
+	}
+
+	key, err := harborClientCacheKey(ctx, cl, harbor, sec)
+	if err != nil {
+		return harborClientCacheEntry{}, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[harbor.UID]
+	c.mu.Unlock()
+
+	if ok && entry.key == key {
+		return entry, nil
+	}
+
+	harborClient, err := internal.BuildClientWithSecret(ctx, cl, harbor, sec)
+	if err != nil {
+		return harborClientCacheEntry{}, err
+	}
+
+	apiClient, err := internal.BuildAPIClientWithSecret(ctx, cl, harbor, sec)
+	if err != nil {
+		return harborClientCacheEntry{}, err
+	}
+
+	entry = harborClientCacheEntry{key: key, client: harborClient, apiClient: apiClient}
+
+	c.mu.Lock()
+	c.entries[harbor.UID] = entry
+	c.mu.Unlock()
+
+	return entry, nil
+}
+
+// Forget removes the cached harbor client of an instance.
+func (c *HarborClientCache) Forget(harbor *v1alpha2.Instance) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, harbor.UID)
+	c.mu.Unlock()
+}
+
+// harborClientCacheKey returns the key a cached harbor client of an instance is valid for,
+// consisting of the instance generation and the resource versions of the referenced credentials and CA bundle.
+func harborClientCacheKey(ctx context.Context, cl client.Client, harbor *v1alpha2.Instance,
+	sec *corev1.Secret) (string, error) {
+	caBundleVersion, err := caBundleResourceVersion(ctx, cl, harbor)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		strconv.FormatInt(harbor.Generation, 10),
+		sec.ResourceVersion,
+		caBundleVersion,
+	}, "/"), nil
+}
+
+// caBundleResourceVersion returns the resource version of the ConfigMap or Secret holding the CA bundle of an instance.
+// Returns an empty string if no CA bundle is referenced or the referenced object does not exist.
+func caBundleResourceVersion(ctx context.Context, cl client.Client, harbor *v1alpha2.Instance) (string, error) {
+	if harbor.Spec.APIClient == nil || harbor.Spec.APIClient.CABundle == nil {
+		return "", nil
+	}
+
+	var (
+		obj  client.Object
+		name string
+	)
+
+	switch source := harbor.Spec.APIClient.CABundle; {
+	case source.ConfigMapKeyRef != nil:
+		obj, name = &corev1.ConfigMap{}, source.ConfigMapKeyRef.Name
+	case source.SecretKeyRef != nil:
+		obj, name = &corev1.Secret{}, source.SecretKeyRef.Name
+	default:
+		return "", nil
+	}
+
+	if _, err := helper.ObjExists(ctx, cl, name, harbor.Namespace, obj); err != nil {
+		return "", err
+	}
+
+	return obj.GetResourceVersion(), nil
+}
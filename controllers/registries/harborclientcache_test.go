@@ -0,0 +1,63 @@
+package registries_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	controllers "github.com/mittwald/harbor-operator/controllers/registries"
+	registriestesting "github.com/mittwald/harbor-operator/controllers/registries/testing"
+)
+
+var _ = Describe("HarborClientCache", func() {
+	var (
+		cache      *controllers.HarborClientCache
+		fakeClient client.Client
+	)
+
+	BeforeEach(func() {
+		secret := registriestesting.CreateSecret("test-harbor-harbor-core", testNamespaceName)
+		fakeClient = fake.NewClientBuilder().WithObjects(&secret).Build()
+		cache = controllers.NewHarborClientCache()
+	})
+
+	It("Should return the cached client while the credentials are unchanged", func() {
+		harbor := registriestesting.CreateInstance("test-harbor", testNamespaceName)
+		harbor.UID = "test-harbor-uid"
+
+		first, err := cache.Get(ctx, fakeClient, harbor)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		second, err := cache.Get(ctx, fakeClient, harbor)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(second).Should(BeIdenticalTo(first))
+	})
+
+	It("Should rebuild the client once the credentials have been rotated", func() {
+		harbor := registriestesting.CreateInstance("test-harbor", testNamespaceName)
+		harbor.UID = "test-harbor-uid"
+
+		first, err := cache.Get(ctx, fakeClient, harbor)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		secret := registriestesting.CreateSecret("test-harbor-harbor-core", testNamespaceName)
+		Ω(fakeClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)).Should(Succeed())
+		secret.Data["HARBOR_ADMIN_PASSWORD"] = []byte("rotated")
+		Ω(fakeClient.Update(ctx, &secret)).Should(Succeed())
+
+		second, err := cache.Get(ctx, fakeClient, harbor)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(second).ShouldNot(BeIdenticalTo(first))
+	})
+
+	It("Should build a new client on every call if no cache is given", func() {
+		var nilCache *controllers.HarborClientCache
+
+		harbor := registriestesting.CreateInstance("test-harbor", testNamespaceName)
+
+		harborClient, err := nilCache.Get(ctx, fakeClient, harbor)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(harborClient).ShouldNot(BeNil())
+	})
+})
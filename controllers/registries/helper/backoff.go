@@ -0,0 +1,21 @@
+package helper
+
+import "time"
+
+// ExponentialBackoff returns the duration to wait before the next attempt after a number of consecutive failures.
+// The base duration is doubled for each additional failure, while the result never exceeds the given maximum.
+func ExponentialBackoff(base, maximum time.Duration, failures int32) time.Duration {
+	if failures <= 1 {
+		return base
+	}
+
+	backoff := base
+	for i := int32(1); i < failures; i++ {
+		backoff *= 2
+		if backoff >= maximum {
+			return maximum
+		}
+	}
+
+	return backoff
+}
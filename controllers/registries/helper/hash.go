@@ -43,14 +43,19 @@ func GenerateHashFromInterfaces(interfaces []interface{}) (InterfaceHash, error)
 	return hash.Sum(nil), nil
 }
 
-// CreateSpecHash returns a hash string constructed with the helm chart spec.
-func CreateSpecHash(spec *helmclient.ChartSpec) (string, error) {
-	hashSrc, err := json.Marshal(spec)
+// CreateSpecHash returns a hash string constructed with the helm chart spec
+// and the versions of sources not being part of it, if any, e.g. the helm chart patches.
+// The options of helm operations are left out, so changing them doesn't upgrade the release.
+func CreateSpecHash(spec *helmclient.ChartSpec, sourceVersions ...string) (string, error) {
+	hashSrc, err := json.Marshal(withoutHelmOptions(spec))
 	if err != nil {
 		return "", err
 	}
 
 	toHash := []interface{}{hashSrc}
+	if len(sourceVersions) > 0 {
+		toHash = append(toHash, sourceVersions)
+	}
 
 	hash, err := GenerateHashFromInterfaces(toHash)
 	if err != nil {
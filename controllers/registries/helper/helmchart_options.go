@@ -0,0 +1,50 @@
+package helper
+
+import (
+	"time"
+
+	helmclient "github.com/mittwald/go-helm-client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// DefaultHelmTimeout is the timeout of helm operations of instances not specifying one.
+const DefaultHelmTimeout = 5 * time.Minute
+
+// ApplyHelmOptions applies the helm operation options of an instance, whose defaults differ from those of
+// the helm client, to its chart spec.
+// The options are not part of the spec hash, so changing them doesn't upgrade the release.
+func ApplyHelmOptions(chartSpec *helmclient.ChartSpec, helmChart *v1alpha2.InstanceHelmChartSpec) {
+	chartSpec.Timeout = DefaultHelmTimeout
+	if helmChart.Timeout != nil && helmChart.Timeout.Duration > 0 {
+		chartSpec.Timeout = helmChart.Timeout.Duration
+	}
+
+	chartSpec.Wait = helmChart.Wait == nil || *helmChart.Wait
+}
+
+// withoutHelmOptions returns a copy of a chart spec without the options of helm operations,
+// which affect how the release is applied rather than what is applied.
+func withoutHelmOptions(chartSpec *helmclient.ChartSpec) *helmclient.ChartSpec {
+	spec := *chartSpec
+
+	spec.Timeout = 0
+	spec.Wait = false
+	spec.WaitForJobs = false
+	spec.Atomic = false
+	spec.MaxHistory = 0
+	spec.CleanupOnFail = false
+	spec.Force = false
+
+	return &spec
+}
+
+// RollbackOnFailure returns true if a failed install or upgrade of the helm release of an instance
+// is to be rolled back by the operator.
+func RollbackOnFailure(helmChart *v1alpha2.InstanceHelmChartSpec) bool {
+	if helmChart.Atomic {
+		return false
+	}
+
+	return helmChart.RollbackOnFailure == nil || *helmChart.RollbackOnFailure
+}
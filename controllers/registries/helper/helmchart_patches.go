@@ -0,0 +1,184 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/postrender"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+const (
+	// kustomizeDir is the directory of the in-memory file system the rendered manifests are kustomized in.
+	kustomizeDir = "/harbor"
+	// renderedManifestsFile is the file holding the manifests rendered by helm.
+	renderedManifestsFile = "manifests.yaml"
+)
+
+// KustomizePostRenderer is a helm post-renderer applying Kustomize patches to the rendered manifests of a chart.
+type KustomizePostRenderer struct {
+	Patches []kustomizetypes.Patch
+}
+
+var _ postrender.PostRenderer = &KustomizePostRenderer{}
+
+// Run applies the patches of the post-renderer to the given manifests in an in-memory kustomization.
+func (r *KustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	fSys := filesys.MakeFsInMemory()
+
+	if err := fSys.WriteFile(filepath.Join(kustomizeDir, renderedManifestsFile), renderedManifests.Bytes()); err != nil {
+		return nil, err
+	}
+
+	kustomization, err := yaml.Marshal(&kustomizetypes.Kustomization{
+		TypeMeta: kustomizetypes.TypeMeta{
+			APIVersion: kustomizetypes.KustomizationVersion,
+			Kind:       kustomizetypes.KustomizationKind,
+		},
+		Resources: []string{renderedManifestsFile},
+		Patches:   r.Patches,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fSys.WriteFile(filepath.Join(kustomizeDir, "kustomization.yaml"), kustomization); err != nil {
+		return nil, err
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, kustomizeDir)
+	if err != nil {
+		return nil, fmt.Errorf("applying helm chart patches failed: %w", err)
+	}
+
+	manifests, err := resMap.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(manifests), nil
+}
+
+// InstanceToPostRenderer returns the helm post-renderer applying the helm chart patches of an instance,
+// or nil if the instance doesn't specify any.
+func InstanceToPostRenderer(ctx context.Context, c client.Client,
+	instance *v1alpha2.Instance) (postrender.PostRenderer, error) {
+	patches, err := getHelmChartPatches(ctx, c, instance)
+	if err != nil || len(patches) == 0 {
+		return nil, err
+	}
+
+	return &KustomizePostRenderer{Patches: patches}, nil
+}
+
+// helmChartPatchesVersion returns a version of the helm chart patches of an instance changing with their content,
+// or an empty string if the instance doesn't specify any.
+func helmChartPatchesVersion(ctx context.Context, c client.Client, instance *v1alpha2.Instance) (string, error) {
+	patches, err := getHelmChartPatches(ctx, c, instance)
+	if err != nil || len(patches) == 0 {
+		return "", err
+	}
+
+	hash, err := GenerateHashFromInterfaces([]interface{}{patches})
+	if err != nil {
+		return "", err
+	}
+
+	return "Patches@" + hash.Short(), nil
+}
+
+// getHelmChartPatches returns the helm chart patches of an instance, reading those referencing a ConfigMap.
+func getHelmChartPatches(ctx context.Context, c client.Client,
+	instance *v1alpha2.Instance) ([]kustomizetypes.Patch, error) {
+	if instance.Spec.HelmChart == nil || len(instance.Spec.HelmChart.Patches) == 0 {
+		return nil, nil
+	}
+
+	patches := make([]kustomizetypes.Patch, 0, len(instance.Spec.HelmChart.Patches))
+
+	for i := range instance.Spec.HelmChart.Patches {
+		spec := &instance.Spec.HelmChart.Patches[i]
+
+		content, err := getHelmChartPatchContent(ctx, c, instance.Namespace, spec)
+		if err != nil {
+			return nil, fmt.Errorf("helm chart patch %d: %w", i, err)
+		}
+
+		if content == "" {
+			continue
+		}
+
+		patch := kustomizetypes.Patch{Patch: content}
+
+		if target := spec.Target; target != nil {
+			patch.Target = &kustomizetypes.Selector{
+				ResId: resid.ResId{
+					Gvk:       resid.Gvk{Group: target.Group, Version: target.Version, Kind: target.Kind},
+					Name:      target.Name,
+					Namespace: target.Namespace,
+				},
+				LabelSelector:      target.LabelSelector,
+				AnnotationSelector: target.AnnotationSelector,
+			}
+		}
+
+		patches = append(patches, patch)
+	}
+
+	return patches, nil
+}
+
+// getHelmChartPatchContent returns the content of a helm chart patch, either given inline or read from a ConfigMap.
+// The content is empty if an optional ConfigMap or key does not exist.
+func getHelmChartPatchContent(ctx context.Context, c client.Client, namespace string,
+	spec *v1alpha2.InstanceHelmChartPatch) (string, error) {
+	switch {
+	case spec.Patch != "" && spec.ConfigMapKeyRef != nil:
+		return "", errors.New("only one of patch and configMapKeyRef may be set")
+	case spec.Patch != "":
+		return spec.Patch, nil
+	case spec.ConfigMapKeyRef == nil:
+		return "", errors.New("one of patch and configMapKeyRef must be set")
+	}
+
+	ref := spec.ConfigMapKeyRef
+
+	var configMap corev1.ConfigMap
+
+	exists, err := ObjExists(ctx, c, ref.Name, namespace, &configMap)
+	if err != nil {
+		return "", err
+	}
+
+	optional := ref.Optional != nil && *ref.Optional
+
+	if !exists {
+		if optional {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("configmap %q does not exist", ref.Name)
+	}
+
+	content, ok := configMap.Data[ref.Key]
+	if !ok {
+		if optional {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("could not find key %s in configmap %s", ref.Key, ref.Name)
+	}
+
+	return content, nil
+}
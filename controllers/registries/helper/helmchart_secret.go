@@ -15,15 +15,41 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func InstanceToChartSpec(ctx context.Context, c client.Client, instance *v1alpha2.Instance) (*helmclient.ChartSpec, error) {
+// InstanceToChartSpec returns the helm chart spec of an instance, with the values of its SecretValues and ValuesFrom
+// sources merged into ValuesYaml. As the helm chart patches of the instance are not part of the chart spec,
+// a version of them is returned alongside, if there are any.
+func InstanceToChartSpec(ctx context.Context, c client.Client,
+	instance *v1alpha2.Instance) (*helmclient.ChartSpec, []string, error) {
+	if instance.Spec.HelmChart == nil {
+		return nil, nil, fmt.Errorf("instance %q does not specify a helm chart", instance.Name)
+	}
+
 	err := enrichChartWithSecretValues(ctx, c, instance)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	err = enrichChartWithValuesFrom(ctx, c, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The patches are applied by a post-renderer rather than being part of the chart spec,
+	// changes of them still require the release to be upgraded.
+	patchesVersion, err := helmChartPatchesVersion(ctx, c, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sourceVersions []string
+	if patchesVersion != "" {
+		sourceVersions = append(sourceVersions, patchesVersion)
 	}
 
-	return &instance.Spec.HelmChart.ChartSpec, nil
+	return &instance.Spec.HelmChart.ChartSpec, sourceVersions, nil
 }
 
+// enrichChartWithSecretValues merges the values of the SecretValues source of an instance into its chart values.
 func enrichChartWithSecretValues(ctx context.Context, c client.Client, instance *v1alpha2.Instance) error {
 	if instance.Spec.HelmChart.SecretValues == nil {
 		return nil
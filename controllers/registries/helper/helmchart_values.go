@@ -0,0 +1,168 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imdario/mergo"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+const (
+	// ValuesFromKindConfigMap is the kind of values sources referencing a ConfigMap.
+	ValuesFromKindConfigMap = "ConfigMap"
+	// ValuesFromKindSecret is the kind of values sources referencing a Secret.
+	ValuesFromKindSecret = "Secret"
+
+	// defaultValuesFromKey is the key of a values source holding the values if none is given.
+	defaultValuesFromKey = "values.yaml"
+)
+
+// enrichChartWithValuesFrom merges the values of the ValuesFrom sources of an instance into its chart values, in order.
+// Missing optional sources and keys are skipped.
+func enrichChartWithValuesFrom(ctx context.Context, c client.Client, instance *v1alpha2.Instance) error {
+	spec := instance.Spec.HelmChart
+
+	if len(spec.ValuesFrom) == 0 {
+		return nil
+	}
+
+	valuesMap, err := spec.ChartSpec.GetValuesMap(nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range spec.ValuesFrom {
+		ref := &spec.ValuesFrom[i]
+
+		data, err := getValuesFromData(ctx, c, instance.Namespace, ref)
+		if err != nil {
+			return err
+		}
+
+		if data == nil {
+			continue
+		}
+
+		sourceValues, err := valuesFromToMap(ref, data)
+		if err != nil {
+			return err
+		}
+
+		err = mergo.Merge(&valuesMap, sourceValues, mergo.WithOverride)
+		if err != nil {
+			return err
+		}
+	}
+
+	newValuesYaml, err := yaml.Marshal(&valuesMap)
+	if err != nil {
+		return err
+	}
+
+	spec.ChartSpec.ValuesYaml = string(newValuesYaml)
+
+	return nil
+}
+
+// getValuesFromData returns the content of the key referenced by a values source.
+// The content is nil if an optional source or key does not exist.
+func getValuesFromData(ctx context.Context, c client.Client, namespace string,
+	ref *v1alpha2.InstanceHelmChartValuesReference) ([]byte, error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultValuesFromKey
+	}
+
+	var (
+		obj  client.Object
+		data map[string][]byte
+	)
+
+	switch ref.Kind {
+	case ValuesFromKindConfigMap:
+		obj = &corev1.ConfigMap{}
+	case ValuesFromKindSecret:
+		obj = &corev1.Secret{}
+	default:
+		return nil, fmt.Errorf("unsupported values source kind %q", ref.Kind)
+	}
+
+	exists, err := ObjExists(ctx, c, ref.Name, namespace, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if ref.Optional {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%s %q does not exist", strings.ToLower(ref.Kind), ref.Name)
+	}
+
+	switch source := obj.(type) {
+	case *corev1.ConfigMap:
+		data = make(map[string][]byte, len(source.Data)+len(source.BinaryData))
+		for k, v := range source.BinaryData {
+			data[k] = v
+		}
+
+		for k, v := range source.Data {
+			data[k] = []byte(v)
+		}
+	case *corev1.Secret:
+		data = source.Data
+	}
+
+	value, ok := data[key]
+	if !ok {
+		if ref.Optional {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%s %q does not have the key %q", strings.ToLower(ref.Kind), ref.Name, key)
+	}
+
+	return value, nil
+}
+
+// valuesFromToMap converts the content of the key referenced by a values source into chart values.
+// The content is injected as a single string value if the source specifies a target path,
+// otherwise it is parsed as a YAML document of chart values.
+func valuesFromToMap(ref *v1alpha2.InstanceHelmChartValuesReference, data []byte) (map[string]interface{}, error) {
+	if ref.TargetPath == "" {
+		var values map[string]interface{}
+
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("parsing values of %s %q failed: %w", strings.ToLower(ref.Kind), ref.Name, err)
+		}
+
+		return values, nil
+	}
+
+	path := strings.Split(ref.TargetPath, ".")
+	for _, segment := range path {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid target path %q", ref.TargetPath)
+		}
+	}
+
+	values := map[string]interface{}{}
+	current := values
+
+	for _, segment := range path[:len(path)-1] {
+		next := map[string]interface{}{}
+		current[segment] = next
+		current = next
+	}
+
+	current[path[len(path)-1]] = string(data)
+
+	return values, nil
+}
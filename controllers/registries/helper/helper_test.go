@@ -1,12 +1,17 @@
 package helper_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
 	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+	registriestesting "github.com/mittwald/harbor-operator/controllers/registries/testing"
 
 	helmclient "github.com/mittwald/go-helm-client"
 	"github.com/stretchr/testify/assert"
@@ -126,6 +131,16 @@ func TestCreateSpecHash(t *testing.T) {
 	if assert.NotNil(t, hash3) {
 		assert.NotEqual(t, hash, hash3)
 	}
+
+	hash4, err := helper.CreateSpecHash(spec, "Patches@00000001")
+
+	assert.NoError(t, err)
+
+	hash5, err := helper.CreateSpecHash(spec, "Patches@00000002")
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash3, hash4)
+	assert.NotEqual(t, hash4, hash5)
 }
 
 func TestObjExists(t *testing.T) {
@@ -147,6 +162,66 @@ func TestObjExists(t *testing.T) {
 	})
 }
 
+func TestGetSecretKeySelectorValue(t *testing.T) {
+	ctx := context.TODO()
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-secret",
+			Namespace: "test-namespace",
+		},
+		Data: map[string][]byte{
+			"clientSecret": []byte(testStr),
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(sec).Build()
+
+	t.Run("ExistingKey", func(t *testing.T) {
+		val, err := helper.GetSecretKeySelectorValue(ctx, fakeClient, sec.Namespace, &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: sec.Name},
+			Key:                  "clientSecret",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, testStr, val)
+	})
+
+	t.Run("ExistingKeyAndVersion", func(t *testing.T) {
+		val, version, err := helper.GetSecretKeySelectorValueAndVersion(ctx, fakeClient, sec.Namespace,
+			&corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: sec.Name},
+				Key:                  "clientSecret",
+			})
+
+		assert.NoError(t, err)
+		assert.Equal(t, testStr, val)
+		assert.Equal(t, sec.ResourceVersion, version)
+		assert.NotEmpty(t, version)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		_, err := helper.GetSecretKeySelectorValue(ctx, fakeClient, sec.Namespace, &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: sec.Name},
+			Key:                  "missing",
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingOptionalSecret", func(t *testing.T) {
+		optional := true
+
+		val, err := helper.GetSecretKeySelectorValue(ctx, fakeClient, sec.Namespace, &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+			Key:                  "clientSecret",
+			Optional:             &optional,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, val)
+	})
+}
+
 func TestGenerateHashFromInterfaces(t *testing.T) {
 	h, err := helper.GenerateHashFromInterfaces([]interface{}{"test", 1})
 	assert.NoError(t, err)
@@ -156,3 +231,344 @@ func TestGenerateHashFromInterfaces(t *testing.T) {
 		assert.Equal(t, h, h2)
 	}
 }
+
+func TestExponentialBackoff(t *testing.T) {
+	base := 30 * time.Second
+	maximum := 10 * time.Minute
+
+	assert.Equal(t, base, helper.ExponentialBackoff(base, maximum, 0))
+	assert.Equal(t, base, helper.ExponentialBackoff(base, maximum, 1))
+	assert.Equal(t, 60*time.Second, helper.ExponentialBackoff(base, maximum, 2))
+	assert.Equal(t, 4*time.Minute, helper.ExponentialBackoff(base, maximum, 4))
+	assert.Equal(t, maximum, helper.ExponentialBackoff(base, maximum, 6))
+	assert.Equal(t, maximum, helper.ExponentialBackoff(base, maximum, 1000))
+}
+
+func TestInstanceToChartSpec_ValuesFrom(t *testing.T) {
+	ctx := context.TODO()
+	ns := "test-namespace"
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "values", Namespace: ns},
+		Data: map[string]string{
+			"values.yaml": "expose:\n  type: ingress\nharborAdminPassword: fromConfigMap\n",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: ns},
+		Data: map[string][]byte{
+			"password": []byte("fromSecret"),
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(configMap, secret).Build()
+
+	newInstance := func(valuesFrom ...v1alpha2.InstanceHelmChartValuesReference) *v1alpha2.Instance {
+		instance := registriestesting.CreateInstance("test-harbor", ns)
+		instance.Spec.HelmChart.ValuesYaml = "expose:\n  type: clusterIP\n  tls:\n    enabled: true\n"
+		instance.Spec.HelmChart.ValuesFrom = valuesFrom
+
+		return instance
+	}
+
+	t.Run("MergedInOrder", func(t *testing.T) {
+		instance := newInstance(
+			v1alpha2.InstanceHelmChartValuesReference{Kind: helper.ValuesFromKindConfigMap, Name: "values"},
+			v1alpha2.InstanceHelmChartValuesReference{
+				Kind:       helper.ValuesFromKindSecret,
+				Name:       "credentials",
+				Key:        "password",
+				TargetPath: "harborAdminPassword",
+			},
+		)
+
+		spec, versions, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		values, err := spec.GetValuesMap(nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]interface{}{
+				"expose": map[string]interface{}{
+					"type": "ingress",
+					"tls":  map[string]interface{}{"enabled": true},
+				},
+				"harborAdminPassword": "fromSecret",
+			}, values)
+		}
+
+		assert.Empty(t, versions)
+	})
+
+	t.Run("MissingOptionalSource", func(t *testing.T) {
+		instance := newInstance(
+			v1alpha2.InstanceHelmChartValuesReference{Kind: helper.ValuesFromKindSecret, Name: "missing", Optional: true},
+			v1alpha2.InstanceHelmChartValuesReference{
+				Kind:     helper.ValuesFromKindConfigMap,
+				Name:     "values",
+				Key:      "missing.yaml",
+				Optional: true,
+			},
+		)
+
+		spec, versions, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		values, err := spec.GetValuesMap(nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "clusterIP", values["expose"].(map[string]interface{})["type"])
+		}
+
+		assert.Empty(t, versions)
+	})
+
+	t.Run("MissingRequiredSource", func(t *testing.T) {
+		instance := newInstance(
+			v1alpha2.InstanceHelmChartValuesReference{Kind: helper.ValuesFromKindSecret, Name: "missing"},
+		)
+
+		_, _, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingRequiredKey", func(t *testing.T) {
+		instance := newInstance(
+			v1alpha2.InstanceHelmChartValuesReference{Kind: helper.ValuesFromKindSecret, Name: "credentials"},
+		)
+
+		_, _, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateSpecHash_ValuesSources(t *testing.T) {
+	ctx := context.TODO()
+	ns := "test-namespace"
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "values", Namespace: ns},
+		Data: map[string][]byte{
+			"values.yaml": []byte("harborAdminPassword: initial\n"),
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	specHash := func() string {
+		instance := registriestesting.CreateInstance("test-harbor", ns)
+		instance.Spec.HelmChart.SecretValues = &v1alpha2.InstanceHelmChartSecretValues{
+			SecretRef: &corev1.LocalObjectReference{Name: "values"},
+			Key:       "values.yaml",
+		}
+
+		spec, versions, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+
+		// The values of the source are only hashed as part of the chart spec, keeping existing hashes stable.
+		assert.Empty(t, versions)
+
+		hash, err := helper.CreateSpecHash(spec, versions...)
+		assert.NoError(t, err)
+
+		return hash
+	}
+
+	hash := specHash()
+
+	secret.Labels = map[string]string{"touched": "true"}
+	assert.NoError(t, fakeClient.Update(ctx, secret))
+	assert.Equal(t, hash, specHash(), "metadata changes of a values source must not change the spec hash")
+
+	secret.Data["values.yaml"] = []byte("harborAdminPassword: rotated\n")
+	assert.NoError(t, fakeClient.Update(ctx, secret))
+	assert.NotEqual(t, hash, specHash(), "value changes of a values source must change the spec hash")
+}
+
+func TestApplyHelmOptions(t *testing.T) {
+	helmChart := &v1alpha2.InstanceHelmChartSpec{}
+	chartSpec := &helmclient.ChartSpec{}
+
+	helper.ApplyHelmOptions(chartSpec, helmChart)
+
+	assert.Equal(t, helper.DefaultHelmTimeout, chartSpec.Timeout)
+	assert.True(t, chartSpec.Wait)
+
+	err := json.Unmarshal([]byte(`{"release":"test-harbor","timeout":"10m","wait":false,"atomic":true}`), helmChart)
+	assert.NoError(t, err)
+
+	helper.ApplyHelmOptions(chartSpec, helmChart)
+
+	assert.Equal(t, 10*time.Minute, chartSpec.Timeout)
+	assert.False(t, chartSpec.Wait)
+	assert.True(t, helmChart.Atomic)
+
+	// Timeouts given in nanoseconds, as with the timeout of the embedded chart spec, are still accepted.
+	helmChart = &v1alpha2.InstanceHelmChartSpec{}
+	err = json.Unmarshal([]byte(`{"release":"test-harbor","timeout":300000000000}`), helmChart)
+	assert.NoError(t, err)
+
+	helper.ApplyHelmOptions(chartSpec, helmChart)
+
+	assert.Equal(t, 5*time.Minute, chartSpec.Timeout)
+	assert.True(t, chartSpec.Wait)
+
+	raw, err := json.Marshal(helmChart)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(raw), `"timeout":"5m0s"`)
+	}
+
+	err = json.Unmarshal([]byte(`{"timeout":"5 minutes"}`), helmChart)
+	assert.Error(t, err)
+}
+
+func TestCreateSpecHash_IgnoresHelmOptions(t *testing.T) {
+	spec := &helmclient.ChartSpec{ReleaseName: "test-harbor", ChartName: "harbor/harbor", Version: "1.14.2"}
+
+	hash, err := helper.CreateSpecHash(spec)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	withOptions := *spec
+	withOptions.Timeout = 10 * time.Minute
+	withOptions.Wait = true
+	withOptions.WaitForJobs = true
+	withOptions.Atomic = true
+	withOptions.MaxHistory = 10
+	withOptions.CleanupOnFail = true
+	withOptions.Force = true
+
+	optionsHash, err := helper.CreateSpecHash(&withOptions)
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, optionsHash)
+	}
+
+	assert.Equal(t, 10*time.Minute, withOptions.Timeout, "the chart spec must not be modified")
+
+	withOptions.Version = "1.15.0"
+
+	versionHash, err := helper.CreateSpecHash(&withOptions)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, hash, versionHash)
+	}
+}
+
+func TestRollbackOnFailure(t *testing.T) {
+	assert.True(t, helper.RollbackOnFailure(&v1alpha2.InstanceHelmChartSpec{}))
+
+	rollbackOnFailure := false
+	assert.False(t, helper.RollbackOnFailure(&v1alpha2.InstanceHelmChartSpec{RollbackOnFailure: &rollbackOnFailure}))
+
+	assert.False(t, helper.RollbackOnFailure(&v1alpha2.InstanceHelmChartSpec{
+		ChartSpec: helmclient.ChartSpec{Atomic: true},
+	}))
+}
+
+func TestInstanceToPostRenderer(t *testing.T) {
+	ctx := context.TODO()
+	ns := "test-namespace"
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "patches", Namespace: ns},
+		Data: map[string]string{
+			"replicas.yaml": "- op: replace\n  path: /spec/replicas\n  value: 3\n",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(configMap).Build()
+
+	rendered := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-harbor-core
+  labels:
+    component: core
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: core
+        image: goharbor/harbor-core
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: test-harbor-core
+spec:
+  type: ClusterIP
+`
+
+	instance := registriestesting.CreateInstance("test-harbor", ns)
+
+	postRenderer, err := helper.InstanceToPostRenderer(ctx, fakeClient, instance)
+	assert.NoError(t, err)
+	assert.Nil(t, postRenderer)
+
+	_, versions, err := helper.InstanceToChartSpec(ctx, fakeClient, instance)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	instance.Spec.HelmChart.Patches = []v1alpha2.InstanceHelmChartPatch{
+		{
+			Patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-harbor-core
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+`,
+		},
+		{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "patches"},
+				Key:                  "replicas.yaml",
+			},
+			Target: &v1alpha2.InstanceHelmChartPatchTarget{Kind: "Deployment", LabelSelector: "component=core"},
+		},
+	}
+
+	postRenderer, err = helper.InstanceToPostRenderer(ctx, fakeClient, instance)
+	if !assert.NoError(t, err) || !assert.NotNil(t, postRenderer) {
+		return
+	}
+
+	manifests, err := postRenderer.Run(bytes.NewBufferString(rendered))
+	if assert.NoError(t, err) {
+		assert.Contains(t, manifests.String(), "replicas: 3")
+		assert.Contains(t, manifests.String(), "runAsNonRoot: true")
+		assert.Contains(t, manifests.String(), "image: goharbor/harbor-core")
+		assert.Contains(t, manifests.String(), "kind: Service")
+	}
+
+	_, versions, err = helper.InstanceToChartSpec(ctx, fakeClient, instance)
+	if assert.NoError(t, err) && assert.Len(t, versions, 1) {
+		assert.Contains(t, versions[0], "Patches@")
+	}
+
+	optional := true
+	instance.Spec.HelmChart.Patches = []v1alpha2.InstanceHelmChartPatch{{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+			Key:                  "patch.yaml",
+			Optional:             &optional,
+		},
+	}}
+
+	postRenderer, err = helper.InstanceToPostRenderer(ctx, fakeClient, instance)
+	assert.NoError(t, err)
+	assert.Nil(t, postRenderer)
+
+	instance.Spec.HelmChart.Patches[0].ConfigMapKeyRef.Optional = nil
+
+	_, err = helper.InstanceToPostRenderer(ctx, fakeClient, instance)
+	assert.EqualError(t, err, `helm chart patch 0: configmap "missing" does not exist`)
+}
@@ -32,3 +32,46 @@ func GetValueFromSecret(sec *corev1.Secret, key string) (string, error) {
 
 	return string(val), nil
 }
+
+// GetSecretKeySelectorValue returns the value of the secret key referenced by a selector.
+// Returns an empty string if an optional secret or key does not exist.
+func GetSecretKeySelectorValue(ctx context.Context, cl client.Client, namespace string,
+	ref *corev1.SecretKeySelector) (string, error) {
+	val, _, err := GetSecretKeySelectorValueAndVersion(ctx, cl, namespace, ref)
+
+	return val, err
+}
+
+// GetSecretKeySelectorValueAndVersion returns the value of the secret key referenced by a selector,
+// alongside the resource version of the secret. Returns empty strings if an optional secret does not exist,
+// and an empty value if an optional key does not exist.
+func GetSecretKeySelectorValueAndVersion(ctx context.Context, cl client.Client, namespace string,
+	ref *corev1.SecretKeySelector) (string, string, error) {
+	optional := ref.Optional != nil && *ref.Optional
+
+	var sec corev1.Secret
+
+	exists, err := ObjExists(ctx, cl, ref.Name, namespace, &sec)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !exists {
+		if optional {
+			return "", "", nil
+		}
+
+		return "", "", fmt.Errorf("secret %q does not exist", ref.Name)
+	}
+
+	val, ok := sec.Data[ref.Key]
+	if !ok {
+		if optional {
+			return "", sec.ResourceVersion, nil
+		}
+
+		return "", "", fmt.Errorf("secret %q does not have the key %q", ref.Name, ref.Key)
+	}
+
+	return string(val), sec.ResourceVersion, nil
+}
@@ -23,7 +23,11 @@ import (
 	"time"
 
 	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -34,6 +38,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -45,16 +50,29 @@ type InstanceReconciler struct {
 	Scheme *runtime.Scheme
 	// helmClientReceiver is a receiver function to generate a helmclient dynamically.
 	HelmClientReceiver HelmClientFactory
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
+	// Recorder records events of instances, e.g. on detected drift of their helm release.
+	Recorder record.EventRecorder
 }
 
 func (r *InstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexInstanceReferences(context.Background(), mgr); err != nil {
+		return err
+	}
+
+	// Changes of referenced secrets and config maps, e.g. holding helm chart values,
+	// are picked up right away instead of with the next periodic reconciliation.
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha2.Instance{}).
+		Watches(&corev1.Secret{}, r.enqueueReferencingInstances(instanceSecretsIndex)).
+		Watches(&corev1.ConfigMap{}, r.enqueueReferencingInstances(instanceConfigMapsIndex)).
 		Complete(r)
 }
 
 // +kubebuilder:rbac:groups=registries.mittwald.de,resources=instances,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=registries.mittwald.de,resources=instances/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets;replicasets,verbs=get;list;watch;create;update;delete;patch
@@ -85,8 +103,11 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	reqLogger = reqLogger.WithValues("instanceName", harbor.Spec.Name)
 	patch := client.MergeFrom(harbor.DeepCopy())
 
+	// Deleted instances are moved into the "Terminating" phase right away.
+	// Failed instances are moved by reconcileFailedInstance instead, backing off failed uninstalls.
 	if harbor.DeletionTimestamp != nil &&
-		harbor.Status.Phase.Name != v1alpha2.InstanceStatusPhaseTerminating {
+		harbor.Status.Phase.Name != v1alpha2.InstanceStatusPhaseTerminating &&
+		harbor.Status.Phase.Name != v1alpha2.InstanceStatusPhaseError {
 		now := metav1.Now()
 		harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
 			Name:           v1alpha2.InstanceStatusPhaseTerminating,
@@ -95,7 +116,7 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 		harbor.Status.SpecHash = ""
 
-		return ctrl.Result{}, r.Client.Status().Patch(ctx, harbor, patch)
+		return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
 	}
 
 	switch harbor.Status.Phase.Name {
@@ -107,96 +128,240 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		harbor.Status.Phase.Message = "project is about to be created"
 		harbor.Status.SpecHash = ""
 
+		if harbor.Spec.IsExternal() {
+			// External instances are not installed via helm.
+			meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionHelmReleaseReady)
+			break
+		}
+
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonInstalling, "helm release is about to be installed")
+
 	case v1alpha2.InstanceStatusPhaseInstalling:
+		if harbor.Spec.IsExternal() {
+			return r.awaitHealthyExternalInstance(ctx, reqLogger, harbor, patch)
+		}
+
+		// Once the helm release has been applied, the instance is not considered
+		// as installed until the Harbor API reports as healthy.
+		if meta.IsStatusConditionTrue(harbor.Status.Conditions, v1alpha2.InstanceConditionHelmReleaseReady) {
+			return r.awaitHealthyInstance(ctx, reqLogger, harbor, patch)
+		}
+
 		reqLogger.Info("Installing Helm chart")
 
-		err := r.updateHelmRepos()
+		chartSpec, sourceVersions, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
 		if err != nil {
-			return ctrl.Result{}, err
+			return r.failInstance(ctx, reqLogger, harbor, patch, fmt.Errorf("building helm chart spec failed: %w", err))
 		}
 
-		chartSpec, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
+		// Storing the spec hash of the chart spec pre-installation
+		// ensures that it is set in "InstanceStatusPhaseInstalled", preventing the controller
+		// to jump right back into "InstanceStatusPhaseInstalling".
+		// It is also used to detect spec changes of instances in "InstanceStatusPhaseError",
+		// hence it is stored before any further step that may fail.
+		specHash, err := helper.CreateSpecHash(chartSpec, sourceVersions...)
 		if err != nil {
-			return ctrl.Result{}, err
+			return r.failInstance(ctx, reqLogger, harbor, patch, fmt.Errorf("creating spec hash failed: %w", err))
+		}
+		harbor.Status.SpecHash = specHash
+
+		if err := r.updateHelmRepos(); err != nil {
+			return r.failInstance(ctx, reqLogger, harbor, patch,
+				fmt.Errorf("updating helm repositories failed: %w", err))
 		}
-		chartSpec.Wait = true
 
-		err = r.installOrUpgradeHelmChart(ctx, chartSpec)
+		helper.ApplyHelmOptions(chartSpec, harbor.Spec.HelmChart)
+
+		// A release stuck in a pending state, e.g. after the operator was restarted during an upgrade,
+		// would fail any further upgrade with "another operation is in progress".
+		if err := r.recoverStuckHelmRelease(reqLogger, harbor, chartSpec); err != nil {
+			return r.failInstance(ctx, reqLogger, harbor, patch, err)
+		}
+
+		now := metav1.Now()
+		harbor.Status.LastAttempt = &now
+
+		postRenderer, err := helper.InstanceToPostRenderer(ctx, r.Client, harbor)
 		if err != nil {
-			return ctrl.Result{RequeueAfter: 60 * time.Second}, err
+			return r.failInstance(ctx, reqLogger, harbor, patch,
+				fmt.Errorf("building helm post-renderer failed: %w", err))
 		}
 
-		harbor.Status.Phase.Name = v1alpha2.InstanceStatusPhaseInstalled
-		harbor.Status.Phase.Message = "harbor was successfully installed"
+		rel, err := r.installOrUpgradeHelmChart(ctx, chartSpec, helper.RollbackOnFailure(harbor.Spec.HelmChart),
+			postRenderer)
+		if err != nil {
+			r.refreshHelmReleaseStatus(reqLogger, harbor, chartSpec)
 
-		// Creating a spec hash of the chart spec pre-installation
-		// ensures that it is set in "InstanceStatusPhaseInstalled", preventing the controller
-		// to jump right back into "InstanceStatusPhaseInstalling"
-		if specHash, err := helper.CreateSpecHash(chartSpec); err != nil {
-			return ctrl.Result{}, err
-		} else if harbor.Status.SpecHash == "" {
-			harbor.Status.SpecHash = specHash
+			setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionFalse,
+				v1alpha2.InstanceReasonInstallFailed, err.Error())
 
-			return ctrl.Result{}, r.Client.Status().Patch(ctx, harbor, patch)
+			return r.failInstance(ctx, reqLogger, harbor, patch, fmt.Errorf("installing helm chart failed: %w", err))
 		}
 
+		harbor.Status.Phase.Message = "helm release was applied, waiting for harbor to become healthy"
+		harbor.Status.HelmRelease = internal.ToHelmReleaseStatus(rel)
+
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionTrue,
+			v1alpha2.InstanceReasonInstallSucceeded, "helm release was successfully applied")
+
+		return ctrl.Result{RequeueAfter: readinessCheckInterval}, r.patchInstanceStatus(ctx, harbor, patch)
+
 	case v1alpha2.InstanceStatusPhaseInstalled:
-		if harbor.Spec.GarbageCollection != nil {
-			if err := r.reconcileGarbageCollection(ctx, harbor); err != nil {
-				return ctrl.Result{RequeueAfter: 60 * time.Second}, err
+		// The finalizer ensures the helm release is uninstalled, which is not needed for external instances.
+		if !harbor.Spec.IsExternal() {
+			controllerutil.AddFinalizer(harbor, internal.FinalizerName)
+			err := r.Client.Patch(ctx, harbor, patch)
+			if err != nil {
+				return ctrl.Result{}, err
 			}
 		}
 
-		controllerutil.AddFinalizer(harbor, internal.FinalizerName)
-		err := r.Client.Patch(ctx, harbor, patch)
-		if err != nil {
-			return ctrl.Result{}, err
+		// A setting failing to sync neither holds back the remaining settings
+		// nor the helm release and health checks of the instance.
+		var errs []error
+
+		for _, reconcileSettings := range r.settingsReconcilers() {
+			if err := reconcileSettings(ctx, harbor); err != nil {
+				errs = append(errs, err)
+			}
 		}
 
-		chartSpec, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
-		if err != nil {
-			return ctrl.Result{}, err
+		if !harbor.Spec.IsExternal() {
+			upgrade, err := r.reconcileInstalledHelmRelease(ctx, reqLogger, harbor)
+			if err != nil {
+				errs = append(errs, err)
+			}
+
+			if upgrade {
+				return ctrl.Result{}, errors.Join(append(errs, r.patchInstanceStatus(ctx, harbor, patch))...)
+			}
 		}
 
-		specHash, err := helper.CreateSpecHash(chartSpec)
-		if err != nil {
+		healthy := r.reconcileHarborHealth(ctx, harbor)
+
+		if err := r.patchInstanceStatus(ctx, harbor, patch); err != nil {
 			return ctrl.Result{}, err
 		}
 
-		if harbor.Status.SpecHash != specHash {
-			harbor.Status.Phase.Name = v1alpha2.InstanceStatusPhaseInstalling
-			harbor.Status.SpecHash = specHash
+		if err := errors.Join(errs...); err != nil {
+			return ctrl.Result{RequeueAfter: 60 * time.Second}, err
+		}
 
-			return ctrl.Result{}, r.Client.Status().Patch(ctx, harbor, patch)
+		if !healthy {
+			reqLogger.Info("waiting till harbor instance is healthy")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 		}
 
+		// Poll the health of installed instances periodically.
+		return ctrl.Result{RequeueAfter: healthCheckInterval()}, nil
+
 	case v1alpha2.InstanceStatusPhaseTerminating:
 		err := r.reconcileTerminatingInstance(ctx, reqLogger, harbor, patch)
 		if err != nil {
-			return ctrl.Result{}, err
+			return r.failInstance(ctx, reqLogger, harbor, patch, err)
 		}
+
+	case v1alpha2.InstanceStatusPhaseError:
+		return r.reconcileFailedInstance(ctx, reqLogger, harbor, patch)
 	}
 
-	return ctrl.Result{}, r.Client.Status().Patch(ctx, harbor, patch)
+	return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
+}
+
+// settingsReconcilers returns the functions syncing the settings of an installed instance with Harbor, in order.
+func (r *InstanceReconciler) settingsReconcilers() []func(context.Context, *v1alpha2.Instance) error {
+	return []func(context.Context, *v1alpha2.Instance) error{
+		r.reconcileGarbageCollection,
+		r.reconcileScanAll,
+		r.reconcileAuditLogPurge,
+		r.reconcileConfiguration,
+		r.reconcileOIDC,
+		r.reconcileLDAP,
+		r.reconcileCVEAllowlist,
+	}
+}
+
+// reconcileInstalledHelmRelease checks the helm release of an installed instance for changes of its chart spec,
+// for being stuck in a pending state and for drift.
+// Returns true if the instance was moved back into "InstanceStatusPhaseInstalling" to upgrade the release.
+func (r *InstanceReconciler) reconcileInstalledHelmRelease(ctx context.Context, log logr.Logger,
+	harbor *v1alpha2.Instance) (bool, error) {
+	chartSpec, sourceVersions, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
+	if err != nil {
+		return false, err
+	}
+
+	specHash, err := helper.CreateSpecHash(chartSpec, sourceVersions...)
+	if err != nil {
+		return false, err
+	}
+
+	if harbor.Status.SpecHash != specHash {
+		now := metav1.Now()
+		harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+			Name:           v1alpha2.InstanceStatusPhaseInstalling,
+			Message:        "helm chart spec changed",
+			LastTransition: &now,
+		}
+		harbor.Status.SpecHash = specHash
+
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonInstalling, "helm chart spec changed, helm release is about to be upgraded")
+
+		return true, nil
+	}
+
+	helper.ApplyHelmOptions(chartSpec, harbor.Spec.HelmChart)
+
+	if err := r.recoverStuckHelmRelease(log, harbor, chartSpec); err != nil {
+		return false, err
+	}
+
+	return r.reconcileHelmReleaseDrift(ctx, log, harbor, chartSpec), nil
+}
+
+// healthCheckInterval returns the configured interval in which installed instances are checked for their health.
+func healthCheckInterval() time.Duration {
+	if config.Config.HealthCheckInterval <= 0 {
+		return config.DefaultHealthCheckInterval
+	}
+
+	return config.Config.HealthCheckInterval
 }
 
 // reconcileTerminatingInstance triggers a helm uninstall for the created release.
+// External instances are released without touching the Harbor installation.
 func (r *InstanceReconciler) reconcileTerminatingInstance(ctx context.Context, log logr.Logger,
 	harbor *v1alpha2.Instance, patch client.Patch) error {
 	if harbor == nil {
 		return errors.New("no harbor instance provided")
 	}
 
-	chartSpec, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
+	r.HarborClients.Forget(harbor)
+
+	if harbor.Spec.IsExternal() {
+		log.Info("pulling finalizer")
+		controllerutil.RemoveFinalizer(harbor, internal.FinalizerName)
+
+		return r.Client.Patch(ctx, harbor, patch)
+	}
+
+	chartSpec, _, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
 	if err != nil {
 		return err
 	}
 
+	helper.ApplyHelmOptions(chartSpec, harbor.Spec.HelmChart)
+
 	log.Info("deleting helm release", "release", chartSpec.ReleaseName)
 
+	now := metav1.Now()
+	harbor.Status.LastAttempt = &now
+
 	err = r.uninstallHelmRelease(chartSpec)
 	if err != nil {
-		return err
+		return fmt.Errorf("uninstalling helm release failed: %w", err)
 	}
 
 	log.Info("pulling finalizer")
@@ -217,28 +382,33 @@ func (r *InstanceReconciler) updateHelmRepos() error {
 }
 
 // installOrUpgradeHelmChart installs and upgrades a helm chart.
-// If the install/upgrade operation fails however, a rollback to the latest release will be performed.
-func (r *InstanceReconciler) installOrUpgradeHelmChart(ctx context.Context, helmChart *helmclient.ChartSpec) error {
+// If the install/upgrade operation fails however, a rollback to the latest release will be performed
+// unless disabled. The rendered manifests are passed through the given post-renderer, if any.
+func (r *InstanceReconciler) installOrUpgradeHelmChart(ctx context.Context, helmChart *helmclient.ChartSpec,
+	rollbackOnFailure bool, postRenderer postrender.PostRenderer) (*release.Release, error) {
 	helmClient, err := r.HelmClientReceiver(config.Config.HelmClientRepositoryCachePath,
 		config.Config.HelmClientRepositoryConfigPath, helmChart.Namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	helmChart.Timeout = 5 * time.Minute
+	var opts *helmclient.GenericHelmOptions
+	if postRenderer != nil {
+		opts = &helmclient.GenericHelmOptions{PostRenderer: postRenderer}
+	}
 
-	upgradedRelease, upgradeErr := helmClient.InstallOrUpgradeChart(ctx, helmChart, nil)
+	upgradedRelease, upgradeErr := helmClient.InstallOrUpgradeChart(ctx, helmChart, opts)
 	if upgradeErr != nil {
-		if upgradedRelease != nil {
+		if upgradedRelease != nil && rollbackOnFailure {
 			rollbackErr := helmClient.RollbackRelease(helmChart)
 			if rollbackErr != nil {
-				return fmt.Errorf("rollback failed: (%s), upgrade failed: %w", rollbackErr, upgradeErr)
+				return nil, fmt.Errorf("rollback failed: (%s), upgrade failed: %w", rollbackErr, upgradeErr)
 			}
 		}
-		return upgradeErr
+		return nil, upgradeErr
 	}
 
-	return nil
+	return upgradedRelease, nil
 }
 
 // uninstallHelmRelease uninstalls a helm release.
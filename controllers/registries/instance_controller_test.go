@@ -1,12 +1,25 @@
 package registries_test
 
 import (
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	helmclient "github.com/mittwald/go-helm-client"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	controllers "github.com/mittwald/harbor-operator/controllers/registries"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
 	registriestesting "github.com/mittwald/harbor-operator/controllers/registries/testing"
 )
 
@@ -39,6 +52,176 @@ var _ = Describe("InstanceController", func() {
 			It("Should not be nil", func() {
 				Ω(instance).ToNot(BeNil())
 			})
+			It("Should persist status conditions", func() {
+				meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+					Type:    v1alpha2.InstanceConditionReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  v1alpha2.InstanceReasonHelmReleaseNotReady,
+					Message: "helm release is not ready",
+				})
+				Ω(k8sClient.Status().Update(ctx, instance)).Should(Succeed())
+				Ω(k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance)).Should(Succeed())
+				Ω(meta.IsStatusConditionFalse(instance.Status.Conditions, v1alpha2.InstanceConditionReady)).Should(BeTrue())
+			})
+		})
+		Context("Instance without a helm chart", func() {
+			It("Should be rejected unless the instance is external", func() {
+				managed := registriestesting.CreateInstance(name+"-no-chart", namespace)
+				managed.Spec.HelmChart = nil
+				err := k8sClient.Create(ctx, managed)
+				Ω(apierrors.IsInvalid(err)).Should(BeTrue())
+				Ω(err.Error()).Should(ContainSubstring("helmChart is required unless the instance is of type external"))
+
+				external := registriestesting.CreateInstance(name+"-external", namespace)
+				external.Spec.Type = v1alpha2.InstanceTypeExternal
+				external.Spec.HelmChart = nil
+				Ω(k8sClient.Create(ctx, external)).Should(Succeed())
+				Ω(k8sClient.Delete(ctx, external)).Should(Succeed())
+			})
+		})
+	})
+	Describe("Failing installation", func() {
+		var (
+			fakeClient client.Client
+			reconciler *controllers.InstanceReconciler
+		)
+		BeforeEach(func() {
+			instance := registriestesting.CreateInstance(name, namespace)
+			instance.Status.Phase.Name = v1alpha2.InstanceStatusPhaseInstalling
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(instance).
+				WithStatusSubresource(instance).
+				Build()
+			reconciler = &controllers.InstanceReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+				Scheme: scheme.Scheme,
+				HelmClientReceiver: func(_, _, _ string) (helmclient.Client, error) {
+					return nil, errors.New("helm repositories are unavailable")
+				},
+			}
+		})
+		It("Should back off further with every failed helm repository update", func() {
+			instance := &v1alpha2.Instance{}
+
+			firstResult, err := reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseError))
+			Ω(instance.Status.FailureCount).Should(BeEquivalentTo(1))
+			Ω(instance.Status.SpecHash).ShouldNot(BeEmpty())
+
+			// Let the backoff pass.
+			lastTransition := metav1.NewTime(time.Now().Add(-time.Hour))
+			instance.Status.Phase.LastTransition = &lastTransition
+			Ω(fakeClient.Status().Update(ctx, instance)).Should(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseInstalling))
+			Ω(instance.Status.FailureCount).Should(BeEquivalentTo(1))
+
+			secondResult, err := reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseError))
+			Ω(instance.Status.FailureCount).Should(BeEquivalentTo(2))
+			Ω(secondResult.RequeueAfter).Should(BeNumerically(">", firstResult.RequeueAfter))
+		})
+	})
+	Describe("Failing upgrade", func() {
+		It("Should record the helm release left behind", func() {
+			instance := registriestesting.CreateInstance(name, namespace)
+			instance.Status.Phase.Name = v1alpha2.InstanceStatusPhaseInstalling
+			instance.Status.HelmRelease = &v1alpha2.InstanceHelmReleaseStatus{
+				Name:      name,
+				Namespace: namespace,
+				Revision:  2,
+				Status:    release.StatusDeployed.String(),
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(instance).
+				WithStatusSubresource(instance).
+				Build()
+			helmClient := &registriestesting.FakeHelmClient{
+				Release: &release.Release{
+					Name:      name,
+					Namespace: namespace,
+					Version:   3,
+					Info:      &release.Info{Status: release.StatusFailed},
+				},
+				InstallErr: errors.New("upgrade failed"),
+			}
+			reconciler := &controllers.InstanceReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+				Scheme: scheme.Scheme,
+				HelmClientReceiver: func(_, _, _ string) (helmclient.Client, error) {
+					return helmClient, nil
+				},
+			}
+
+			_, err := reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseError))
+			Ω(instance.Status.HelmRelease).ShouldNot(BeNil())
+			Ω(instance.Status.HelmRelease.Revision).Should(Equal(3))
+			Ω(instance.Status.HelmRelease.Status).Should(Equal(release.StatusFailed.String()))
+		})
+	})
+	Describe("Deleting a failed instance", func() {
+		newReconciler := func(lastTransition time.Time) (*controllers.InstanceReconciler, client.Client) {
+			deleted := metav1.NewTime(time.Now().Add(-time.Minute))
+			failed := metav1.NewTime(lastTransition)
+
+			instance := registriestesting.CreateInstance(name, namespace)
+			instance.Finalizers = []string{internal.FinalizerName}
+			instance.DeletionTimestamp = &deleted
+			instance.Status.Phase = v1alpha2.InstanceStatusPhase{
+				Name:           v1alpha2.InstanceStatusPhaseError,
+				LastTransition: &failed,
+			}
+			instance.Status.FailureCount = 5
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(instance).
+				WithStatusSubresource(instance).
+				Build()
+
+			return &controllers.InstanceReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+				Scheme: scheme.Scheme,
+			}, fakeClient
+		}
+		It("Should not wait for the backoff of a failed installation", func() {
+			reconciler, fakeClient := newReconciler(time.Now().Add(-2 * time.Minute))
+
+			result, err := reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.RequeueAfter).Should(BeZero())
+
+			instance := &v1alpha2.Instance{}
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseTerminating))
+			Ω(instance.Status.FailureCount).Should(BeZero())
+		})
+		It("Should wait for the backoff of a failed uninstallation", func() {
+			reconciler, fakeClient := newReconciler(time.Now())
+
+			result, err := reconciler.Reconcile(ctx, request)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.RequeueAfter).Should(BeNumerically(">", 0))
+
+			instance := &v1alpha2.Instance{}
+			Ω(fakeClient.Get(ctx, request.NamespacedName, instance)).Should(Succeed())
+			Ω(instance.Status.Phase.Name).Should(Equal(v1alpha2.InstanceStatusPhaseError))
 		})
 	})
 })
@@ -0,0 +1,131 @@
+package registries
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/config"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileHelmReleaseDrift compares the deployed helm release of an instance with its desired chart spec.
+// The result is reflected in the "DriftDetected" condition of the instance.
+// If the release has diverged, e.g. by a manual upgrade or rollback, the instance is moved back into
+// "InstanceStatusPhaseInstalling" to re-apply the release and true is returned.
+func (r *InstanceReconciler) reconcileHelmReleaseDrift(ctx context.Context, log logr.Logger,
+	harbor *v1alpha2.Instance, chartSpec *helmclient.ChartSpec) bool {
+	rel, drift, err := r.getHelmReleaseDrift(harbor, chartSpec)
+	if err != nil {
+		log.Error(err, "checking helm release for drift failed")
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionDriftDetected, metav1.ConditionUnknown,
+			v1alpha2.InstanceReasonDriftCheckFailed, err.Error())
+
+		return false
+	}
+
+	if drift == "" {
+		updateHelmReleaseStatus(harbor, rel)
+
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionDriftDetected, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonNoDrift, "helm release matches the desired chart spec")
+
+		return false
+	}
+
+	log.Info("helm release drift detected, re-applying helm release", "drift", drift)
+	r.Recorder.Event(harbor, corev1.EventTypeWarning, v1alpha2.InstanceReasonDriftDetected, drift)
+
+	// The drifted release is recorded as is, e.g. the revision of a manual rollback or none if it was uninstalled.
+	harbor.Status.HelmRelease = internal.ToHelmReleaseStatus(rel)
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionDriftDetected, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonDriftDetected, drift)
+
+	now := metav1.Now()
+	harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+		Name:           v1alpha2.InstanceStatusPhaseInstalling,
+		Message:        "helm release drift detected",
+		LastTransition: &now,
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionFalse,
+		v1alpha2.InstanceReasonDriftDetected, "helm release drifted, helm release is about to be re-applied")
+
+	return true
+}
+
+// updateHelmReleaseStatus records the deployed helm release of an instance in its status.
+// While the release is pending, only its status is updated, so the revision applied by the operator last
+// is kept for detecting drift once the pending operation has finished.
+func updateHelmReleaseStatus(harbor *v1alpha2.Instance, rel *release.Release) {
+	if rel == nil {
+		return
+	}
+
+	if rel.Info != nil && rel.Info.Status.IsPending() && harbor.Status.HelmRelease != nil {
+		harbor.Status.HelmRelease.Status = rel.Info.Status.String()
+		return
+	}
+
+	harbor.Status.HelmRelease = internal.ToHelmReleaseStatus(rel)
+}
+
+// refreshHelmReleaseStatus records the current helm release of an instance in its status after a failed
+// helm operation, which may have left a failed or rolled back revision behind.
+func (r *InstanceReconciler) refreshHelmReleaseStatus(log logr.Logger, harbor *v1alpha2.Instance,
+	chartSpec *helmclient.ChartSpec) {
+	helmClient, err := r.HelmClientReceiver(config.Config.HelmClientRepositoryCachePath,
+		config.Config.HelmClientRepositoryConfigPath, chartSpec.Namespace)
+	if err != nil {
+		log.Error(err, "reading helm release failed")
+		return
+	}
+
+	rel, err := helmClient.GetRelease(chartSpec.ReleaseName)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		log.Error(err, "reading helm release failed")
+		return
+	}
+
+	harbor.Status.HelmRelease = internal.ToHelmReleaseStatus(rel)
+}
+
+// getHelmReleaseDrift returns the deployed helm release of an instance along with a description of how it
+// diverges from its desired chart spec, or an empty string if it does not.
+func (r *InstanceReconciler) getHelmReleaseDrift(harbor *v1alpha2.Instance,
+	chartSpec *helmclient.ChartSpec) (*release.Release, string, error) {
+	helmClient, err := r.HelmClientReceiver(config.Config.HelmClientRepositoryCachePath,
+		config.Config.HelmClientRepositoryConfigPath, chartSpec.Namespace)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rel *release.Release
+
+	rel, err = helmClient.GetRelease(chartSpec.ReleaseName)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, "", err
+	}
+
+	values, err := chartSpec.GetValuesMap(helmClient.GetProviders())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var appliedRevision int
+	if harbor.Status.HelmRelease != nil {
+		appliedRevision = harbor.Status.HelmRelease.Revision
+	}
+
+	drift, err := internal.HelmReleaseDrift(rel, chartSpec, values, appliedRevision)
+
+	return rel, drift, err
+}
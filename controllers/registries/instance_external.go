@@ -0,0 +1,36 @@
+package registries
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// awaitHealthyExternalInstance moves an instance pointing to an externally managed Harbor installation
+// into the "Installed" phase, as soon as its API is reachable and reports as healthy.
+// As there is no helm release to be retried, the API is polled until it becomes healthy.
+func (r *InstanceReconciler) awaitHealthyExternalInstance(ctx context.Context, log logr.Logger,
+	harbor *v1alpha2.Instance, patch client.Patch) (ctrl.Result, error) {
+	if !r.reconcileHarborHealth(ctx, harbor) {
+		log.Info("waiting till external harbor instance is healthy")
+
+		harbor.Status.Phase.Message = "waiting for the external harbor API to become healthy"
+
+		return ctrl.Result{RequeueAfter: readinessCheckInterval}, r.patchInstanceStatus(ctx, harbor, patch)
+	}
+
+	now := metav1.Now()
+	harbor.Status.FailureCount = 0
+	harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+		Name:           v1alpha2.InstanceStatusPhaseInstalled,
+		Message:        "external harbor is reachable and healthy",
+		LastTransition: &now,
+	}
+
+	return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
+}
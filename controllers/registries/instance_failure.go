@@ -0,0 +1,130 @@
+package registries
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+)
+
+const (
+	// failureBackoffBase is the duration to wait before retrying the first failed operation of an instance.
+	failureBackoffBase = 30 * time.Second
+	// failureBackoffMax is the maximum duration to wait before retrying a failed operation of an instance.
+	failureBackoffMax = 30 * time.Minute
+)
+
+// failInstance moves an instance into the "Error" phase after a failed operation
+// and schedules the next attempt using an exponential backoff.
+func (r *InstanceReconciler) failInstance(ctx context.Context, log logr.Logger, harbor *v1alpha2.Instance,
+	patch client.Patch, err error) (ctrl.Result, error) {
+	log.Error(err, "instance operation failed", "failureCount", harbor.Status.FailureCount+1)
+
+	now := metav1.Now()
+	harbor.Status.FailureCount++
+	harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+		Name:           v1alpha2.InstanceStatusPhaseError,
+		Message:        err.Error(),
+		LastTransition: &now,
+	}
+
+	return ctrl.Result{RequeueAfter: failureBackoff(harbor)}, r.patchInstanceStatus(ctx, harbor, patch)
+}
+
+// reconcileFailedInstance retries the failed operation of an instance in the "Error" phase once its backoff has passed.
+// Changes to the helm chart spec of an instance that is not being deleted are picked up immediately,
+// just like the deletion of an instance that failed to be installed.
+func (r *InstanceReconciler) reconcileFailedInstance(ctx context.Context, log logr.Logger,
+	harbor *v1alpha2.Instance, patch client.Patch) (ctrl.Result, error) {
+	now := metav1.Now()
+
+	if harbor.DeletionTimestamp != nil {
+		if failedToUninstall(harbor) {
+			if remaining := remainingBackoff(harbor, now.Time); remaining > 0 {
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+		} else {
+			// The backoff of failed installations doesn't delay the deletion.
+			harbor.Status.FailureCount = 0
+		}
+
+		log.Info("retrying deletion of failed instance")
+
+		harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+			Name:           v1alpha2.InstanceStatusPhaseTerminating,
+			Message:        "retrying deletion",
+			LastTransition: &now,
+		}
+
+		return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
+	}
+
+	specHash := harbor.Status.SpecHash
+
+	if !harbor.Spec.IsExternal() {
+		chartSpec, sourceVersions, err := helper.InstanceToChartSpec(ctx, r.Client, harbor)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		specHash, err = helper.CreateSpecHash(chartSpec, sourceVersions...)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if specHash != harbor.Status.SpecHash {
+		log.Info("helm chart spec changed, recovering failed instance")
+
+		// Storing the recomputed hash ensures a further failure of the unchanged spec is retried with backoff.
+		harbor.Status.FailureCount = 0
+		harbor.Status.SpecHash = specHash
+	} else if remaining := remainingBackoff(harbor, now.Time); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	} else {
+		log.Info("retrying installation of failed instance", "failureCount", harbor.Status.FailureCount)
+	}
+
+	harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+		Name:           v1alpha2.InstanceStatusPhaseInstalling,
+		Message:        "retrying installation",
+		LastTransition: &now,
+	}
+
+	if !harbor.Spec.IsExternal() {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionHelmReleaseReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonInstalling, "helm release is about to be installed")
+	}
+
+	return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
+}
+
+// failedToUninstall returns true if an instance in the "Error" phase failed to be uninstalled
+// rather than installed, i.e. it failed after its deletion was requested.
+func failedToUninstall(harbor *v1alpha2.Instance) bool {
+	if harbor.DeletionTimestamp == nil || harbor.Status.Phase.LastTransition == nil {
+		return false
+	}
+
+	return harbor.Status.Phase.LastTransition.After(harbor.DeletionTimestamp.Time)
+}
+
+// failureBackoff returns the duration to wait before retrying the failed operation of an instance.
+func failureBackoff(harbor *v1alpha2.Instance) time.Duration {
+	return helper.ExponentialBackoff(failureBackoffBase, failureBackoffMax, harbor.Status.FailureCount)
+}
+
+// remainingBackoff returns the remaining duration to wait before the failed operation of an instance is retried.
+func remainingBackoff(harbor *v1alpha2.Instance, now time.Time) time.Duration {
+	if harbor.Status.Phase.LastTransition == nil {
+		return 0
+	}
+
+	return harbor.Status.Phase.LastTransition.Add(failureBackoff(harbor)).Sub(now)
+}
@@ -0,0 +1,113 @@
+package registries
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+const (
+	// defaultReadinessTimeout is the duration to wait for Harbor to become healthy after installation,
+	// unless specified otherwise via the instance spec.
+	defaultReadinessTimeout = 10 * time.Minute
+	// readinessCheckInterval is the interval in which the health of an instance is checked while installing.
+	readinessCheckInterval = 10 * time.Second
+)
+
+// awaitHealthyInstance moves an instance whose helm release has been applied into the "Installed" phase,
+// as soon as the Harbor API reports as healthy.
+// If Harbor does not become healthy within the readiness timeout, the instance is moved into the "Error" phase.
+func (r *InstanceReconciler) awaitHealthyInstance(ctx context.Context, log logr.Logger, harbor *v1alpha2.Instance,
+	patch client.Patch) (ctrl.Result, error) {
+	now := metav1.Now()
+
+	if r.reconcileHarborHealth(ctx, harbor) {
+		harbor.Status.FailureCount = 0
+		harbor.Status.Phase = v1alpha2.InstanceStatusPhase{
+			Name:           v1alpha2.InstanceStatusPhaseInstalled,
+			Message:        "harbor was successfully installed",
+			LastTransition: &now,
+		}
+
+		return ctrl.Result{}, r.patchInstanceStatus(ctx, harbor, patch)
+	}
+
+	timeout := readinessTimeout(harbor)
+	released := meta.FindStatusCondition(harbor.Status.Conditions, v1alpha2.InstanceConditionHelmReleaseReady)
+
+	if released != nil && now.Sub(released.LastTransitionTime.Time) > timeout {
+		healthCondition := meta.FindStatusCondition(harbor.Status.Conditions, v1alpha2.InstanceConditionHarborAPIHealthy)
+
+		return r.failInstance(ctx, log, harbor, patch,
+			fmt.Errorf("harbor did not become healthy within %s after the helm release was applied: %s",
+				timeout, healthCondition.Message))
+	}
+
+	log.Info("waiting till harbor instance is healthy")
+
+	return ctrl.Result{RequeueAfter: readinessCheckInterval}, r.patchInstanceStatus(ctx, harbor, patch)
+}
+
+// readinessTimeout returns the duration to wait for an instance to become healthy after installation.
+func readinessTimeout(harbor *v1alpha2.Instance) time.Duration {
+	if harbor.Spec.ReadinessTimeout == nil || harbor.Spec.ReadinessTimeout.Duration <= 0 {
+		return defaultReadinessTimeout
+	}
+
+	return harbor.Spec.ReadinessTimeout.Duration
+}
+
+// reconcileHarborHealth queries the health endpoint of the Harbor API and records the health of each
+// component in the instance status, reflecting the result in the "HarborAPIHealthy" and "Degraded" conditions.
+// Returns true if the instance reported as healthy.
+func (r *InstanceReconciler) reconcileHarborHealth(ctx context.Context, harbor *v1alpha2.Instance) bool {
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		setHarborAPIUnreachable(harbor, err)
+		return false
+	}
+
+	health, err := harborClient.GetHealth(ctx)
+	if err != nil {
+		setHarborAPIUnreachable(harbor, err)
+		return false
+	}
+
+	harbor.Status.Components = internal.ToComponentStatuses(health.Components, metav1.Now())
+
+	unhealthyComponents := internal.GetUnhealthyComponents(health.Components)
+	if health.Status != internal.HealthStatusHealthy || len(unhealthyComponents) > 0 {
+		msg := fmt.Sprintf("unhealthy components: %q", unhealthyComponents)
+
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionHarborAPIHealthy, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonUnhealthy, msg)
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionDegraded, metav1.ConditionTrue,
+			v1alpha2.InstanceReasonComponentsUnhealthy, msg)
+
+		return false
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionHarborAPIHealthy, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonHealthy, "all harbor components are healthy")
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionDegraded, metav1.ConditionFalse,
+		v1alpha2.InstanceReasonComponentsHealthy, "all harbor components are healthy")
+
+	return true
+}
+
+// setHarborAPIUnreachable marks the Harbor API of an instance as unreachable.
+func setHarborAPIUnreachable(harbor *v1alpha2.Instance, err error) {
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionHarborAPIHealthy, metav1.ConditionFalse,
+		v1alpha2.InstanceReasonAPIUnreachable, err.Error())
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionDegraded, metav1.ConditionUnknown,
+		v1alpha2.InstanceReasonAPIUnreachable, err.Error())
+}
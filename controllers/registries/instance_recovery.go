@@ -0,0 +1,111 @@
+package registries
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/config"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// recoverStuckHelmRelease recovers the helm release of an instance if it has been stuck in a pending state
+// for longer than the configured pending release timeout, which blocks any further helm operation.
+// The release is rolled back to its preceding revision if that has been deployed successfully,
+// otherwise it is marked as failed, allowing it to be upgraded again.
+// The recovery is recorded in the instance status as well as in an event.
+func (r *InstanceReconciler) recoverStuckHelmRelease(log logr.Logger, harbor *v1alpha2.Instance,
+	chartSpec *helmclient.ChartSpec) error {
+	helmClient, err := r.HelmClientReceiver(config.Config.HelmClientRepositoryCachePath,
+		config.Config.HelmClientRepositoryConfigPath, chartSpec.Namespace)
+	if err != nil {
+		return err
+	}
+
+	rel, err := helmClient.GetRelease(chartSpec.ReleaseName)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	timeout := pendingReleaseTimeout()
+
+	if !internal.IsHelmReleaseStuck(rel, timeout, time.Now()) {
+		return nil
+	}
+
+	stuckStatus := rel.Info.Status.String()
+
+	log.Info("recovering stuck helm release", "release", rel.Name, "revision", rel.Version, "status", stuckStatus)
+
+	// The history of releases is not limited by helm.
+	history, err := helmClient.ListReleaseHistory(chartSpec.ReleaseName, 0)
+	if err != nil {
+		return err
+	}
+
+	action := v1alpha2.HelmReleaseRecoveryActionMarkedFailed
+	message := fmt.Sprintf("helm release revision %d was stuck in status %q for more than %s and was marked as failed",
+		rel.Version, stuckStatus, timeout)
+
+	if internal.CanRollbackHelmRelease(rel, history) {
+		action = v1alpha2.HelmReleaseRecoveryActionRolledBack
+		message = fmt.Sprintf("helm release revision %d was stuck in status %q for more than %s and was rolled back "+
+			"to revision %d", rel.Version, stuckStatus, timeout, rel.Version-1)
+
+		err = helmClient.RollbackRelease(chartSpec)
+	} else {
+		err = markHelmReleaseFailed(helmClient, rel, message)
+	}
+
+	if err != nil {
+		r.Recorder.Eventf(harbor, corev1.EventTypeWarning, v1alpha2.InstanceReasonHelmReleaseRecoveryFailed,
+			"recovering helm release revision %d stuck in status %q failed: %s", rel.Version, stuckStatus, err)
+
+		return fmt.Errorf("recovering stuck helm release failed: %w", err)
+	}
+
+	r.Recorder.Event(harbor, corev1.EventTypeWarning, v1alpha2.InstanceReasonHelmReleaseRecovered, message)
+
+	harbor.Status.HelmReleaseRecovery = &v1alpha2.InstanceHelmReleaseRecoveryStatus{
+		Action:   action,
+		Revision: rel.Version,
+		Status:   stuckStatus,
+		Time:     metav1.Now(),
+		Message:  message,
+	}
+
+	return nil
+}
+
+// markHelmReleaseFailed sets the status of a helm release to "failed" in the helm release storage.
+func markHelmReleaseFailed(helmClient helmclient.Client, rel *release.Release, description string) error {
+	client, ok := helmClient.(*helmclient.HelmClient)
+	if !ok {
+		return errors.New("helm client does not support updating releases")
+	}
+
+	rel.SetStatus(release.StatusFailed, description)
+
+	return client.ActionConfig.Releases.Update(rel)
+}
+
+// pendingReleaseTimeout returns the configured duration after which helm releases stuck in a pending state
+// are recovered.
+func pendingReleaseTimeout() time.Duration {
+	if config.Config.PendingReleaseTimeout <= 0 {
+		return config.DefaultPendingReleaseTimeout
+	}
+
+	return config.Config.PendingReleaseTimeout
+}
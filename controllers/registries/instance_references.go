@@ -0,0 +1,72 @@
+package registries
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+const (
+	// instanceSecretsIndex indexes instances by the names of the secrets they reference.
+	instanceSecretsIndex = "spec.secretRefs"
+	// instanceConfigMapsIndex indexes instances by the names of the config maps they reference.
+	instanceConfigMapsIndex = "spec.configMapRefs"
+)
+
+// indexInstanceReferences indexes instances by the names of the secrets and config maps they reference,
+// allowing to enqueue instances once a referenced object changes.
+func indexInstanceReferences(ctx context.Context, mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha2.Instance{}, instanceSecretsIndex,
+		func(obj client.Object) []string {
+			harbor, ok := obj.(*v1alpha2.Instance)
+			if !ok {
+				return nil
+			}
+
+			return internal.InstanceSecretNames(harbor)
+		}); err != nil {
+		return err
+	}
+
+	return mgr.GetFieldIndexer().IndexField(ctx, &v1alpha2.Instance{}, instanceConfigMapsIndex,
+		func(obj client.Object) []string {
+			harbor, ok := obj.(*v1alpha2.Instance)
+			if !ok {
+				return nil
+			}
+
+			return internal.InstanceConfigMapNames(harbor)
+		})
+}
+
+// enqueueReferencingInstances returns a handler enqueueing the instances referencing
+// a changed object via the given index.
+func (r *InstanceReconciler) enqueueReferencingInstances(index string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var instances v1alpha2.InstanceList
+
+		if err := r.Client.List(ctx, &instances, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{index: obj.GetName()}); err != nil {
+			r.Log.Error(err, "listing instances referencing object failed",
+				"namespace", obj.GetNamespace(), "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(instances.Items))
+		for i := range instances.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: instances.Items[i].Namespace,
+				Name:      instances.Items[i].Name,
+			}})
+		}
+
+		return requests
+	})
+}
@@ -0,0 +1,76 @@
+package registries
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// setInstanceCondition sets a condition on the status of an instance,
+// stamping it with the generation of the instance that has been observed.
+func setInstanceCondition(harbor *v1alpha2.Instance, conditionType string, status metav1.ConditionStatus,
+	reason, message string) {
+	meta.SetStatusCondition(&harbor.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: harbor.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateReadyCondition derives the summarizing "Ready" condition from the remaining conditions of an instance.
+func updateReadyCondition(harbor *v1alpha2.Instance) {
+	conditions := harbor.Status.Conditions
+
+	switch {
+	case harbor.Status.Phase.Name == v1alpha2.InstanceStatusPhaseTerminating:
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonTerminating, "harbor instance is being deleted")
+	case !harbor.Spec.IsExternal() &&
+		!meta.IsStatusConditionTrue(conditions, v1alpha2.InstanceConditionHelmReleaseReady):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonHelmReleaseNotReady, "helm release is not ready")
+	case !meta.IsStatusConditionTrue(conditions, v1alpha2.InstanceConditionHarborAPIHealthy):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonHarborAPIUnhealthy, "harbor API is not healthy")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionGarbageCollectionSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonGarbageCollectionNotSynced, "garbage collection schedule is not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionScanAllSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonScanAllNotSynced, "scan all schedule is not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionAuditLogPurgeSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonAuditLogPurgeNotSynced, "audit log purge schedule is not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionConfigurationSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonConfigurationNotSynced, "system configuration is not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionOIDCSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonOIDCNotSynced, "OIDC settings are not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionLDAPSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonLDAPNotSynced, "LDAP settings are not synced")
+	case meta.IsStatusConditionFalse(conditions, v1alpha2.InstanceConditionCVEAllowlistSynced):
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonCVEAllowlistNotSynced, "system CVE allowlist is not synced")
+	default:
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionReady, metav1.ConditionTrue,
+			v1alpha2.InstanceReasonReady, "harbor instance is ready")
+	}
+}
+
+// patchInstanceStatus updates the observed generation as well as the "Ready" condition
+// of an instance and patches its status subresource.
+func (r *InstanceReconciler) patchInstanceStatus(ctx context.Context, harbor *v1alpha2.Instance,
+	patch client.Patch) error {
+	harbor.Status.ObservedGeneration = harbor.Generation
+	updateReadyCondition(harbor)
+
+	return r.Client.Status().Patch(ctx, harbor, patch)
+}
@@ -2,6 +2,12 @@ package internal
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	h "github.com/mittwald/goharbor-client/v5/apiv2"
@@ -13,29 +19,282 @@ import (
 	"github.com/mittwald/harbor-operator/controllers/registries/helper"
 )
 
+const (
+	// DefaultAdminUsername is the name of the Harbor admin user, unless specified otherwise via the instance spec.
+	DefaultAdminUsername = "admin"
+	// DefaultAdminPasswordKey is the secret key holding the password of the Harbor admin user,
+	// unless specified otherwise via the instance spec.
+	DefaultAdminPasswordKey = "HARBOR_ADMIN_PASSWORD"
+
+	// defaultClientTimeout is the timeout of requests to the Harbor API,
+	// unless specified otherwise via the instance spec.
+	defaultClientTimeout = 10 * time.Second
+	// defaultClientPageSize is the page size used when listing resources via the Harbor API,
+	// unless specified otherwise via the instance spec.
+	defaultClientPageSize = 10
+)
+
 // BuildClient builds a harbor client to interact with the API
-// using the default (admin) credentials of an existing harbor instance.
+// using the (admin) credentials of an existing harbor instance.
 func BuildClient(ctx context.Context, cl client.Client,
 	harbor *v1alpha2.Instance) (*h.RESTClient, error) {
+	sec, err := GetAdminCredentialsSecret(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildClientWithSecret(ctx, cl, harbor, sec)
+}
+
+// BuildClientWithSecret builds a harbor client to interact with the API
+// using the (admin) credentials stored in the given secret.
+func BuildClientWithSecret(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance, sec *corev1.Secret) (*h.RESTClient, error) {
+	username, password, err := AdminCredentialsFromSecret(harbor, sec)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := clientOptions(harbor)
+
+	httpClient, err := buildHTTPClient(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		return h.NewRESTClientForHost(harbor.Spec.InstanceURL+"/api", username, password, opts)
+	}
+
+	return h.NewRESTClientForHostWithClient(harbor.Spec.InstanceURL+"/api", username, password, opts, httpClient)
+}
+
+// BuildAPIClient builds a client to interact with the endpoints of the harbor API
+// which are not covered by the REST client, using the (admin) credentials of an existing harbor instance.
+func BuildAPIClient(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (*APIClient, error) {
+	sec, err := GetAdminCredentialsSecret(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildAPIClientWithSecret(ctx, cl, harbor, sec)
+}
+
+// BuildAPIClientWithSecret builds a client to interact with the endpoints of the harbor API
+// which are not covered by the REST client, using the (admin) credentials stored in the given secret.
+func BuildAPIClientWithSecret(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance, sec *corev1.Secret) (*APIClient, error) {
+	username, password, err := AdminCredentialsFromSecret(harbor, sec)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := buildHTTPClient(ctx, cl, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &APIClient{
+		baseURL:    strings.TrimSuffix(harbor.Spec.InstanceURL, "/") + "/api/v2.0",
+		username:   username,
+		password:   password,
+		timeout:    clientOptions(harbor).Timeout,
+		httpClient: httpClient,
+	}, nil
+}
+
+// buildHTTPClient returns the HTTP client used to access the Harbor API, if the instance spec requires a
+// custom TLS configuration. Returns nil if the default HTTP client is sufficient.
+func buildHTTPClient(ctx context.Context, cl client.Client, harbor *v1alpha2.Instance) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(ctx, cl, harbor)
+	if err != nil || tlsConfig == nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// clientOptions returns the options of the Harbor API client, as specified via the instance spec.
+func clientOptions(harbor *v1alpha2.Instance) *clientconfig.Options {
+	opts := clientconfig.Options{
+		Timeout:  defaultClientTimeout,
+		PageSize: defaultClientPageSize,
+	}
+
+	if spec := harbor.Spec.APIClient; spec != nil {
+		if spec.Timeout != nil && spec.Timeout.Duration > 0 {
+			opts.Timeout = spec.Timeout.Duration
+		}
+
+		if spec.PageSize > 0 {
+			opts.PageSize = spec.PageSize
+		}
+	}
+
+	return &opts
+}
+
+// buildTLSConfig returns the TLS configuration used to access the Harbor API,
+// trusting the CA bundle referenced in the instance spec in addition to the system trust store.
+// Returns nil if the default TLS configuration is sufficient.
+func buildTLSConfig(ctx context.Context, cl client.Client, harbor *v1alpha2.Instance) (*tls.Config, error) {
+	spec := harbor.Spec.APIClient
+	if spec == nil || (spec.CABundle == nil && !spec.InsecureSkipVerify) {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Skipping the verification has to be requested explicitly via the instance spec.
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+
+	if spec.CABundle == nil {
+		return tlsConfig, nil
+	}
+
+	caBundle, err := getCABundle(ctx, cl, harbor.Namespace, spec.CABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(caBundle) == 0 {
+		return tlsConfig, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("the CA bundle does not contain any valid PEM-encoded certificate")
+	}
+
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
+
+// getCABundle reads the CA bundle from the referenced ConfigMap or Secret key.
+// Returns an empty bundle if an optional source does not exist.
+func getCABundle(ctx context.Context, cl client.Client, namespace string,
+	source *v1alpha2.InstanceCABundleSource) ([]byte, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+
+		var cm corev1.ConfigMap
+
+		exists, err := helper.ObjExists(ctx, cl, ref.Name, namespace, &cm)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			if optional {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("configmap %q does not exist", ref.Name)
+		}
+
+		if val, ok := cm.Data[ref.Key]; ok {
+			return []byte(val), nil
+		}
+
+		if val, ok := cm.BinaryData[ref.Key]; ok {
+			return val, nil
+		}
+
+		if optional {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("configmap %q does not have the key %q", ref.Name, ref.Key)
+
+	case source.SecretKeyRef != nil:
+		val, err := helper.GetSecretKeySelectorValue(ctx, cl, namespace, source.SecretKeyRef)
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(val), nil
+	}
+
+	return nil, nil
+}
+
+// GetAdminCredentials returns the username and password of the admin user of a harbor instance,
+// read from the secret referenced in the instance spec.
+func GetAdminCredentials(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (username, password string, err error) {
+	sec, err := GetAdminCredentialsSecret(ctx, cl, harbor)
+	if err != nil {
+		return "", "", err
+	}
+
+	return AdminCredentialsFromSecret(harbor, sec)
+}
+
+// GetAdminCredentialsSecret returns the secret holding the admin credentials of a harbor instance.
+func GetAdminCredentialsSecret(ctx context.Context, cl client.Client,
+	harbor *v1alpha2.Instance) (*corev1.Secret, error) {
 	sec := &corev1.Secret{}
 
 	err := cl.Get(ctx, client.ObjectKey{
-		Name:      harbor.Name + "-harbor-core",
+		Name:      AdminCredentialsSecretName(harbor),
 		Namespace: harbor.Namespace,
 	}, sec)
 	if err != nil {
 		return nil, err
 	}
 
-	corePassword, err := helper.GetValueFromSecret(sec, "HARBOR_ADMIN_PASSWORD")
+	return sec, nil
+}
+
+// AdminCredentialsFromSecret returns the username and password of the admin user of a harbor instance
+// stored in the given secret, using the keys specified in the instance spec.
+func AdminCredentialsFromSecret(harbor *v1alpha2.Instance,
+	sec *corev1.Secret) (username, password string, err error) {
+	username = DefaultAdminUsername
+	passwordKey := DefaultAdminPasswordKey
+
+	if creds := harbor.Spec.AdminCredentials; creds != nil {
+		if creds.UsernameKey != "" {
+			username, err = helper.GetValueFromSecret(sec, creds.UsernameKey)
+			if err != nil {
+				return "", "", err
+			}
+		}
+
+		if creds.PasswordKey != "" {
+			passwordKey = creds.PasswordKey
+		}
+	}
+
+	password, err = helper.GetValueFromSecret(sec, passwordKey)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
-	opts := clientconfig.Options{
-		Timeout:  10 * time.Second,
-		PageSize: 10,
+	return username, password, nil
+}
+
+// AdminCredentialsSecretName returns the name of the secret holding the admin credentials of a harbor instance.
+func AdminCredentialsSecretName(harbor *v1alpha2.Instance) string {
+	if creds := harbor.Spec.AdminCredentials; creds != nil && creds.SecretRef != nil && creds.SecretRef.Name != "" {
+		return creds.SecretRef.Name
 	}
 
-	return h.NewRESTClientForHost(harbor.Spec.InstanceURL+"/api", "admin", corePassword, &opts)
+	return harbor.Name + "-harbor-core"
 }
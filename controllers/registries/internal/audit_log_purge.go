@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// Job parameters of the audit log purge schedule, as used by the Harbor API.
+const (
+	auditLogPurgeRetentionHourParam     = "audit_retention_hour"
+	auditLogPurgeIncludeOperationsParam = "include_operations"
+	auditLogPurgeDryRunParam            = "dry_run"
+)
+
+// auditLogPurgeParameters are the job parameters of an audit log purge schedule held by Harbor.
+type auditLogPurgeParameters struct {
+	RetentionHour     int64  `json:"audit_retention_hour"`
+	IncludeOperations string `json:"include_operations"`
+}
+
+// ToAuditLogPurgeSchedule constructs the audit log purge schedule of Harbor from an audit log purge spec,
+// using the given (validated) schedule type.
+func ToAuditLogPurgeSchedule(spec *v1alpha2.AuditLogPurge, scheduleType v1alpha2.ScheduleType) *model.Schedule {
+	return &model.Schedule{
+		Schedule: &model.ScheduleObj{
+			Cron: spec.Cron,
+			Type: string(scheduleType),
+		},
+		Parameters: map[string]interface{}{
+			auditLogPurgeRetentionHourParam:     spec.RetentionHours,
+			auditLogPurgeIncludeOperationsParam: AuditLogPurgeOperations(spec.IncludeOperations),
+			auditLogPurgeDryRunParam:            false,
+		},
+	}
+}
+
+// AuditLogPurgeOperations returns the comma-separated, sorted and deduplicated operations whose audit logs are purged.
+// Defaults to all operations if none are given.
+func AuditLogPurgeOperations(operations []v1alpha2.AuditLogOperation) string {
+	if len(operations) == 0 {
+		operations = []v1alpha2.AuditLogOperation{
+			v1alpha2.AuditLogOperationCreate,
+			v1alpha2.AuditLogOperationDelete,
+			v1alpha2.AuditLogOperationPull,
+		}
+	}
+
+	ops := make([]string, 0, len(operations))
+	for _, op := range operations {
+		ops = append(ops, string(op))
+	}
+
+	return normalizeOperations(ops)
+}
+
+// AuditLogPurgeScheduleMatches returns true if the audit log purge schedule held by Harbor
+// matches the given schedule, ignoring read-only fields.
+func AuditLogPurgeScheduleMatches(held *model.ExecHistory, desired *model.Schedule) bool {
+	if held == nil || held.Schedule == nil {
+		return false
+	}
+
+	if held.Schedule.Cron != desired.Schedule.Cron || held.Schedule.Type != desired.Schedule.Type {
+		return false
+	}
+
+	var params auditLogPurgeParameters
+	if err := json.Unmarshal([]byte(held.JobParameters), &params); err != nil {
+		// Unparsable parameters are overwritten by the desired ones.
+		return false
+	}
+
+	return params.RetentionHour == desired.Parameters[auditLogPurgeRetentionHourParam] &&
+		normalizeOperations(strings.Split(params.IncludeOperations, ",")) ==
+			desired.Parameters[auditLogPurgeIncludeOperationsParam]
+}
+
+// normalizeOperations returns the comma-separated, sorted and deduplicated non-empty operations.
+func normalizeOperations(operations []string) string {
+	seen := make(map[string]bool, len(operations))
+	result := make([]string, 0, len(operations))
+
+	for _, op := range operations {
+		op = strings.TrimSpace(op)
+		if op == "" || seen[op] {
+			continue
+		}
+
+		seen[op] = true
+		result = append(result, op)
+	}
+
+	sort.Strings(result)
+
+	return strings.Join(result, ",")
+}
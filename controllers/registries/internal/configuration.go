@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+)
+
+// configurationItem is a single item of the system configuration, as returned by the Harbor configurations API.
+type configurationItem struct {
+	Editable bool            `json:"editable"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// DiffConfiguration compares the desired system configuration items to the current configuration of a harbor
+// instance. Returns the values of the items which differ and have to be updated, as well as the items which are
+// unsupported or cannot be changed, sorted by their key.
+func DiffConfiguration(desired map[string]apiextensionsv1.JSON,
+	current *model.ConfigurationsResponse) (map[string]interface{}, []v1alpha2.InstanceRejectedConfiguration, error) {
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var currentItems map[string]configurationItem
+
+	if err := json.Unmarshal(currentJSON, &currentItems); err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	update := make(map[string]interface{})
+
+	var rejected []v1alpha2.InstanceRejectedConfiguration
+
+	for _, key := range keys {
+		var desiredValue interface{}
+
+		if err := json.Unmarshal(desired[key].Raw, &desiredValue); err != nil {
+			rejected = append(rejected, rejectConfiguration(key, "invalid value: %s", err))
+			continue
+		}
+
+		item, ok := currentItems[key]
+		if !ok {
+			rejected = append(rejected, rejectConfiguration(key, "unsupported configuration item"))
+			continue
+		}
+
+		var currentValue interface{}
+
+		if err := json.Unmarshal(item.Value, &currentValue); err != nil {
+			return nil, nil, err
+		}
+
+		if reflect.DeepEqual(desiredValue, currentValue) {
+			continue
+		}
+
+		if !item.Editable {
+			rejected = append(rejected, rejectConfiguration(key, "configuration item is not editable"))
+			continue
+		}
+
+		if _, err := ToConfigurations(map[string]interface{}{key: desiredValue}); err != nil {
+			rejected = append(rejected, rejectConfiguration(key, "invalid value: %s", err))
+			continue
+		}
+
+		update[key] = desiredValue
+	}
+
+	return update, rejected, nil
+}
+
+// ToConfigurations converts system configuration values, keyed by the names used by the Harbor
+// configurations API, to the corresponding model used to update the configuration.
+func ToConfigurations(values map[string]interface{}) (*model.Configurations, error) {
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg model.Configurations
+
+	if err := json.Unmarshal(valuesJSON, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// ConfigurationValues converts the items set in a system configuration model to configuration values,
+// keyed by the names used by the Harbor configurations API. The given keys are omitted.
+func ConfigurationValues(cfg *model.Configurations, omitKeys ...string) (map[string]apiextensionsv1.JSON, error) {
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawValues map[string]json.RawMessage
+
+	if err := json.Unmarshal(cfgJSON, &rawValues); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]apiextensionsv1.JSON, len(rawValues))
+
+	for key, raw := range rawValues {
+		values[key] = apiextensionsv1.JSON{Raw: raw}
+	}
+
+	for _, key := range omitKeys {
+		delete(values, key)
+	}
+
+	return values, nil
+}
+
+// ConfigurationHash returns a hash of configuration values, which must not contain write-only items like secrets,
+// and the resource version of the secret holding the write-only items. This detects changes of the secret
+// without publishing a hash of its content.
+func ConfigurationHash(values map[string]apiextensionsv1.JSON, secretVersion string) (string, error) {
+	hash, err := helper.GenerateHashFromInterfaces([]interface{}{values, secretVersion})
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+func rejectConfiguration(key, format string, args ...interface{}) v1alpha2.InstanceRejectedConfiguration {
+	return v1alpha2.InstanceRejectedConfiguration{
+		Key:    key,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// GetSystemCVEAllowlist returns the system-wide CVE allowlist of Harbor.
+func (c *APIClient) GetSystemCVEAllowlist(ctx context.Context) (*model.CVEAllowlist, error) {
+	var allowlist model.CVEAllowlist
+
+	if err := c.do(ctx, http.MethodGet, "/system/CVEAllowlist", nil, &allowlist); err != nil {
+		return nil, err
+	}
+
+	return &allowlist, nil
+}
+
+// UpdateSystemCVEAllowlist replaces the system-wide CVE allowlist of Harbor.
+func (c *APIClient) UpdateSystemCVEAllowlist(ctx context.Context, allowlist *model.CVEAllowlist) error {
+	return c.do(ctx, http.MethodPut, "/system/CVEAllowlist", allowlist, nil)
+}
+
+// PartitionCVEAllowlist splits the items of a CVE allowlist into the sorted and deduplicated IDs of the CVEs
+// that are allowlisted at the given time, and the items that have expired.
+func PartitionCVEAllowlist(allowlist *v1alpha2.CVEAllowlist,
+	now time.Time) (active []string, expired []v1alpha2.CVEAllowlistItem) {
+	if allowlist == nil {
+		return nil, nil
+	}
+
+	activeIDs := make(map[string]bool, len(allowlist.Items))
+
+	for i := range allowlist.Items {
+		item := allowlist.Items[i]
+		if item.ExpiresAt == nil || now.Before(item.ExpiresAt.Time) {
+			activeIDs[item.ID] = true
+		}
+	}
+
+	for i := range allowlist.Items {
+		item := allowlist.Items[i]
+		if !activeIDs[item.ID] {
+			expired = append(expired, *item.DeepCopy())
+		}
+	}
+
+	for id := range activeIDs {
+		active = append(active, id)
+	}
+
+	sort.Strings(active)
+
+	return active, expired
+}
+
+// NextCVEAllowlistExpiry returns the duration until the next item of a CVE allowlist expires.
+// Returns 0 if none of the items expires in the future.
+func NextCVEAllowlistExpiry(allowlist *v1alpha2.CVEAllowlist, now time.Time) time.Duration {
+	var next time.Duration
+
+	if allowlist == nil {
+		return next
+	}
+
+	for i := range allowlist.Items {
+		if allowlist.Items[i].ExpiresAt == nil {
+			continue
+		}
+
+		if remaining := allowlist.Items[i].ExpiresAt.Sub(now); remaining > 0 && (next == 0 || remaining < next) {
+			next = remaining
+		}
+	}
+
+	return next
+}
+
+// CVEAllowlistMatches returns true if a Harbor CVE allowlist holds exactly the given sorted CVE IDs
+// and does not expire as a whole.
+func CVEAllowlistMatches(allowlist *model.CVEAllowlist, ids []string) bool {
+	if allowlist == nil {
+		return len(ids) == 0
+	}
+
+	return allowlist.ExpiresAt == nil && slices.Equal(CVEAllowlistIDs(allowlist), ids)
+}
+
+// CVEAllowlistIDs returns the sorted and deduplicated IDs of the CVEs of a Harbor CVE allowlist.
+func CVEAllowlistIDs(allowlist *model.CVEAllowlist) []string {
+	if allowlist == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(allowlist.Items))
+	ids := make([]string, 0, len(allowlist.Items))
+
+	for _, item := range allowlist.Items {
+		if item == nil || seen[item.CVEID] {
+			continue
+		}
+
+		seen[item.CVEID] = true
+		ids = append(ids, item.CVEID)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// ToCVEAllowlistItems converts CVE IDs into the items of a Harbor CVE allowlist.
+func ToCVEAllowlistItems(ids []string) []*model.CVEAllowlistItem {
+	items := make([]*model.CVEAllowlistItem, 0, len(ids))
+
+	for _, id := range ids {
+		items = append(items, &model.CVEAllowlistItem{CVEID: id})
+	}
+
+	return items
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// garbageCollectionFreedSpaceRegex matches the log line of a garbage collection job reporting the freed space,
+// e.g. "The GC job actual frees up 34 MB space." or, for dry runs, "The GC could free up 34 MB space, ...".
+var garbageCollectionFreedSpaceRegex = regexp.MustCompile(`frees? up (\d+) MB space`)
+
+// Job parameters of the garbage collection, as used by the Harbor API.
+const (
+	garbageCollectionDeleteUntaggedParam = "delete_untagged"
+	garbageCollectionDryRunParam         = "dry_run"
+	garbageCollectionWorkersParam        = "workers"
+)
+
+// garbageCollectionParameters are the job parameters of a garbage collection.
+type garbageCollectionParameters struct {
+	DeleteUntagged bool   `json:"delete_untagged"`
+	DryRun         bool   `json:"dry_run"`
+	Workers        *int64 `json:"workers"`
+}
+
+// GarbageCollectionParameters returns the job parameters of a garbage collection configured by the given spec.
+// The number of workers is left to Harbor if not set.
+func GarbageCollectionParameters(spec *v1alpha2.GarbageCollection) map[string]interface{} {
+	params := map[string]interface{}{
+		garbageCollectionDeleteUntaggedParam: false,
+		garbageCollectionDryRunParam:         false,
+	}
+
+	if spec == nil {
+		return params
+	}
+
+	params[garbageCollectionDeleteUntaggedParam] = spec.DeleteUntagged
+	params[garbageCollectionDryRunParam] = spec.DryRun
+
+	if spec.Workers != nil {
+		params[garbageCollectionWorkersParam] = *spec.Workers
+	}
+
+	return params
+}
+
+// ToGarbageCollectionSchedule constructs the garbage collection schedule of Harbor from a garbage collection spec,
+// using the given (validated) schedule type.
+func ToGarbageCollectionSchedule(spec *v1alpha2.GarbageCollection, scheduleType v1alpha2.ScheduleType) *model.Schedule {
+	return &model.Schedule{
+		Schedule: &model.ScheduleObj{
+			Cron: spec.Cron,
+			Type: string(scheduleType),
+		},
+		Parameters: GarbageCollectionParameters(spec),
+	}
+}
+
+// GarbageCollectionScheduleMatches returns true if the garbage collection schedule held by Harbor matches the given
+// schedule, including its job parameters and ignoring read-only fields.
+// The number of workers is only compared if it is set in the given schedule.
+func GarbageCollectionScheduleMatches(held *model.GCHistory, desired *model.Schedule) bool {
+	if held == nil || held.Schedule == nil {
+		return false
+	}
+
+	if held.Schedule.Cron != desired.Schedule.Cron || held.Schedule.Type != desired.Schedule.Type {
+		return false
+	}
+
+	heldParams, err := parseGarbageCollectionParameters(held.JobParameters)
+	if err != nil {
+		// Unparsable parameters are overwritten by the desired ones.
+		return false
+	}
+
+	desiredJSON, err := json.Marshal(desired.Parameters)
+	if err != nil {
+		return false
+	}
+
+	desiredParams, err := parseGarbageCollectionParameters(string(desiredJSON))
+	if err != nil {
+		return false
+	}
+
+	if desiredParams.Workers != nil &&
+		(heldParams.Workers == nil || *heldParams.Workers != *desiredParams.Workers) {
+		return false
+	}
+
+	return heldParams.DeleteUntagged == desiredParams.DeleteUntagged && heldParams.DryRun == desiredParams.DryRun
+}
+
+// parseGarbageCollectionParameters parses the JSON encoded job parameters of a garbage collection.
+func parseGarbageCollectionParameters(params string) (*garbageCollectionParameters, error) {
+	var result garbageCollectionParameters
+
+	if params == "" {
+		return &result, nil
+	}
+
+	if err := json.Unmarshal([]byte(params), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListGarbageCollectionExecutions returns the latest executions of the garbage collection job, the most recent first.
+func (c *APIClient) ListGarbageCollectionExecutions(ctx context.Context, limit int32) ([]*model.GCHistory, error) {
+	var executions []*model.GCHistory
+
+	path := fmt.Sprintf("/system/gc?page=1&page_size=%d&sort=-creation_time", limit)
+	if err := c.do(ctx, http.MethodGet, path, nil, &executions); err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// GetGarbageCollectionLog returns the job log of the garbage collection execution with the given ID.
+func (c *APIClient) GetGarbageCollectionLog(ctx context.Context, id int64) (string, error) {
+	var log string
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/system/gc/%d/log", id), nil, &log); err != nil {
+		return "", err
+	}
+
+	return log, nil
+}
+
+// ToGarbageCollectionExecution converts a garbage collection execution reported by the Harbor API
+// into an execution of the instance status.
+func ToGarbageCollectionExecution(execution *model.GCHistory) v1alpha2.GarbageCollectionExecution {
+	result := v1alpha2.GarbageCollectionExecution{
+		ID:        execution.ID,
+		Status:    execution.JobStatus,
+		StartTime: toMetaTime(execution.CreationTime),
+	}
+
+	if IsGarbageCollectionFinished(execution.JobStatus) {
+		result.EndTime = toMetaTime(execution.UpdateTime)
+	}
+
+	if params, err := parseGarbageCollectionParameters(execution.JobParameters); err == nil {
+		result.DryRun = params.DryRun
+	}
+
+	return result
+}
+
+// IsGarbageCollectionFinished returns true if the given job status denotes a finished garbage collection execution.
+func IsGarbageCollectionFinished(status string) bool {
+	switch strings.ToLower(status) {
+	case "success", "error", "stopped":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsGarbageCollectionSucceeded returns true if the given job status denotes a successful garbage collection execution.
+func IsGarbageCollectionSucceeded(status string) bool {
+	return strings.EqualFold(status, "success")
+}
+
+// ParseGarbageCollectionFreedSpace returns the space freed by a garbage collection execution, as reported in its job log.
+// Returns nil if the log does not report the freed space.
+func ParseGarbageCollectionFreedSpace(log string) *resource.Quantity {
+	match := garbageCollectionFreedSpaceRegex.FindStringSubmatch(log)
+	if match == nil {
+		return nil
+	}
+
+	mb, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	// Harbor reports the freed space in units of 1024*1024 bytes.
+	return resource.NewQuantity(mb*1024*1024, resource.BinarySI)
+}
+
+// toMetaTime converts a timestamp of the Harbor API, returning nil for unset timestamps.
+func toMetaTime(t strfmt.DateTime) *metav1.Time {
+	if time.Time(t).IsZero() {
+		return nil
+	}
+
+	result := metav1.NewTime(time.Time(t))
+
+	return &result
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+)
+
+// APIClient interacts with endpoints of the Harbor v2 API which are not covered by the goharbor-client.
+type APIClient struct {
+	baseURL    string
+	username   string
+	password   string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// APIError is returned for unsuccessful responses of the Harbor API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// PingLDAP tests the connection to an LDAP server using the given settings.
+func (c *APIClient) PingLDAP(ctx context.Context, conf *model.LdapConf) (*model.LdapPingResult, error) {
+	var result model.LdapPingResult
+
+	if err := c.do(ctx, http.MethodPost, "/ldap/ping", conf, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// do sends a request to the Harbor API, encoding the given body as JSON and decoding the response into out.
+// Plain text responses, e.g. job logs, are read into out if it is a *string.
+func (c *APIClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+
+	if body != nil {
+		bodyJSON, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewReader(bodyJSON)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+
+	text, isText := out.(*string)
+	if isText {
+		req.Header.Set("Accept", "text/plain")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Message:    string(bytes.TrimSpace(respBody)),
+		}
+	}
+
+	if isText {
+		*text = string(respBody)
+		return nil
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, out)
+}
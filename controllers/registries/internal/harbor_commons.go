@@ -6,6 +6,7 @@ import (
 
 	h "github.com/mittwald/goharbor-client/v5/apiv2"
 	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	registriesv1alpha2 "github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
@@ -13,7 +14,12 @@ import (
 	"github.com/mittwald/harbor-operator/controllers/registries/helper"
 )
 
-const FinalizerName = "registries.mittwald.de/finalizer"
+const (
+	FinalizerName = "registries.mittwald.de/finalizer"
+
+	// HealthStatusHealthy is the status reported by the Harbor API for healthy components.
+	HealthStatusHealthy = "healthy"
+)
 
 func AssertHealthyHarborInstance(ctx context.Context, harborClient *h.RESTClient) error {
 	health, err := harborClient.GetHealth(ctx)
@@ -21,7 +27,7 @@ func AssertHealthyHarborInstance(ctx context.Context, harborClient *h.RESTClient
 		return err
 	}
 
-	if health.Status != "healthy" {
+	if health.Status != HealthStatusHealthy {
 		unhealthyComponents := GetUnhealthyComponents(health.Components)
 		err := fmt.Errorf("unhealthy components: %q", unhealthyComponents)
 		return err
@@ -34,7 +40,7 @@ func AssertHealthyHarborInstance(ctx context.Context, harborClient *h.RESTClient
 func GetUnhealthyComponents(status []*model.ComponentHealthStatus) []string {
 	var unhealthyComponents []string
 	for _, c := range status {
-		if c.Status != "healthy" {
+		if c.Status != HealthStatusHealthy {
 			unhealthyComponents = append(unhealthyComponents, c.Name)
 		}
 	}
@@ -42,6 +48,27 @@ func GetUnhealthyComponents(status []*model.ComponentHealthStatus) []string {
 	return unhealthyComponents
 }
 
+// ToComponentStatuses converts the component health reported by the Harbor API
+// into component statuses of an instance, stamped with the time of the health check.
+func ToComponentStatuses(components []*model.ComponentHealthStatus,
+	checkTime metav1.Time) []registriesv1alpha2.InstanceComponentStatus {
+	statuses := make([]registriesv1alpha2.InstanceComponentStatus, 0, len(components))
+	for _, c := range components {
+		if c == nil {
+			continue
+		}
+
+		statuses = append(statuses, registriesv1alpha2.InstanceComponentStatus{
+			Name:          c.Name,
+			Status:        c.Status,
+			Error:         c.Error,
+			LastCheckTime: checkTime,
+		})
+	}
+
+	return statuses
+}
+
 // GetOperationalHarborInstance returns a harbor instance if it exists.
 // Returns an error if the instance could not be found or is not in the 'Installed' phase.
 func GetOperationalHarborInstance(ctx context.Context, instanceKey client.ObjectKey, cl client.Client) (*registriesv1alpha2.Instance, error) {
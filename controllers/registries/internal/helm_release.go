@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// ToHelmReleaseStatus converts a helm release into its representation in the instance status.
+func ToHelmReleaseStatus(rel *release.Release) *v1alpha2.InstanceHelmReleaseStatus {
+	if rel == nil {
+		return nil
+	}
+
+	status := &v1alpha2.InstanceHelmReleaseStatus{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+	}
+
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		status.Chart = rel.Chart.Metadata.Name
+		status.ChartVersion = rel.Chart.Metadata.Version
+		status.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+
+	if rel.Info != nil {
+		status.Status = rel.Info.Status.String()
+
+		if !rel.Info.LastDeployed.IsZero() {
+			lastDeployed := metav1.NewTime(rel.Info.LastDeployed.Time)
+			status.LastDeployed = &lastDeployed
+		}
+	}
+
+	return status
+}
+
+// HelmReleaseDrift returns a description of how a deployed helm release diverges from the desired chart spec
+// and values, or an empty string if it does not.
+// The revision of the release is compared against the given revision applied by the operator last, unless it is 0.
+// Pending releases are not considered as diverged, as they are still being operated on.
+func HelmReleaseDrift(rel *release.Release, spec *helmclient.ChartSpec, values map[string]interface{},
+	appliedRevision int) (string, error) {
+	if rel == nil {
+		return "helm release does not exist", nil
+	}
+
+	if rel.Info != nil {
+		if rel.Info.Status.IsPending() {
+			return "", nil
+		}
+
+		if rel.Info.Status != release.StatusDeployed {
+			return fmt.Sprintf("helm release is in status %q", rel.Info.Status), nil
+		}
+	}
+
+	if appliedRevision != 0 && rel.Version != appliedRevision {
+		return fmt.Sprintf("helm release revision changed from %d to %d", appliedRevision, rel.Version), nil
+	}
+
+	if rel.Chart != nil && rel.Chart.Metadata != nil && !chartVersionMatches(rel.Chart.Metadata.Version, spec.Version) {
+		return fmt.Sprintf("chart version %q differs from the desired version %q",
+			rel.Chart.Metadata.Version, spec.Version), nil
+	}
+
+	matches, err := valuesMatch(rel.Config, values)
+	if err != nil {
+		return "", err
+	}
+
+	if !matches {
+		return "helm release values differ from the desired values", nil
+	}
+
+	return "", nil
+}
+
+// chartVersionMatches returns true if a deployed chart version matches the desired version.
+// Desired versions which are not exact, e.g. unset or version constraints, match any deployed version.
+func chartVersionMatches(deployed, desired string) bool {
+	desiredVersion, err := semver.StrictNewVersion(strings.TrimPrefix(desired, "v"))
+	if err != nil {
+		return true
+	}
+
+	deployedVersion, err := semver.NewVersion(deployed)
+	if err != nil {
+		return false
+	}
+
+	return deployedVersion.Equal(desiredVersion)
+}
+
+// valuesMatch returns true if the user-supplied values of a deployed release match the desired values.
+// Both are compared in their JSON representation, as the numeric types of decoded values may differ.
+func valuesMatch(deployed, desired map[string]interface{}) (bool, error) {
+	normalizedDeployed, err := normalizeValues(deployed)
+	if err != nil {
+		return false, err
+	}
+
+	normalizedDesired, err := normalizeValues(desired)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(normalizedDeployed, normalizedDesired), nil
+}
+
+// normalizeValues returns the given values in their JSON representation, treating nil values as empty.
+func normalizeValues(values map[string]interface{}) (map[string]interface{}, error) {
+	normalized := map[string]interface{}{}
+
+	if len(values) == 0 {
+		return normalized, nil
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// IsHelmReleaseStuck returns true if a helm release has been in a pending state for longer than the given timeout,
+// e.g. as the operator was restarted while the release was being upgraded.
+func IsHelmReleaseStuck(rel *release.Release, timeout time.Duration, now time.Time) bool {
+	if rel == nil || rel.Info == nil || !rel.Info.Status.IsPending() {
+		return false
+	}
+
+	return now.Sub(rel.Info.LastDeployed.Time) > timeout
+}
+
+// CanRollbackHelmRelease returns true if the revision preceding the given helm release has been deployed successfully,
+// as helm rolls back to the preceding revision.
+func CanRollbackHelmRelease(rel *release.Release, history []*release.Release) bool {
+	for _, historyRelease := range history {
+		if historyRelease.Version != rel.Version-1 || historyRelease.Info == nil {
+			continue
+		}
+
+		switch historyRelease.Info.Status {
+		case release.StatusDeployed, release.StatusSuperseded:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"sort"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+)
+
+// InstanceSecretNames returns the sorted and deduplicated names of the secrets referenced by an instance,
+// e.g. by its helm chart values sources, its admin credentials or its OIDC and LDAP settings.
+func InstanceSecretNames(harbor *v1alpha2.Instance) []string {
+	var names []string
+
+	if chart := harbor.Spec.HelmChart; chart != nil {
+		if chart.SecretValues != nil && chart.SecretValues.SecretRef != nil {
+			names = append(names, chart.SecretValues.SecretRef.Name)
+		}
+
+		names = append(names, valuesFromNames(chart.ValuesFrom, helper.ValuesFromKindSecret)...)
+	}
+
+	// The admin credentials secret defaults to the one created by the helm chart,
+	// rotating it requires the cached Harbor API client of the instance to be rebuilt.
+	names = append(names, AdminCredentialsSecretName(harbor))
+
+	if apiClient := harbor.Spec.APIClient; apiClient != nil && apiClient.CABundle != nil &&
+		apiClient.CABundle.SecretKeyRef != nil {
+		names = append(names, apiClient.CABundle.SecretKeyRef.Name)
+	}
+
+	if harbor.Spec.OIDC != nil {
+		names = append(names, harbor.Spec.OIDC.ClientSecretRef.Name)
+	}
+
+	if harbor.Spec.LDAP != nil && harbor.Spec.LDAP.BindPasswordRef != nil {
+		names = append(names, harbor.Spec.LDAP.BindPasswordRef.Name)
+	}
+
+	return sortedUniqueNames(names)
+}
+
+// InstanceConfigMapNames returns the sorted and deduplicated names of the config maps referenced by an instance,
+// e.g. by its helm chart values sources and patches.
+func InstanceConfigMapNames(harbor *v1alpha2.Instance) []string {
+	var names []string
+
+	if chart := harbor.Spec.HelmChart; chart != nil {
+		names = append(names, valuesFromNames(chart.ValuesFrom, helper.ValuesFromKindConfigMap)...)
+
+		for i := range chart.Patches {
+			if chart.Patches[i].ConfigMapKeyRef != nil {
+				names = append(names, chart.Patches[i].ConfigMapKeyRef.Name)
+			}
+		}
+	}
+
+	if apiClient := harbor.Spec.APIClient; apiClient != nil && apiClient.CABundle != nil &&
+		apiClient.CABundle.ConfigMapKeyRef != nil {
+		names = append(names, apiClient.CABundle.ConfigMapKeyRef.Name)
+	}
+
+	return sortedUniqueNames(names)
+}
+
+// valuesFromNames returns the names of the helm chart values sources of the given kind.
+func valuesFromNames(valuesFrom []v1alpha2.InstanceHelmChartValuesReference, kind string) []string {
+	var names []string
+
+	for i := range valuesFrom {
+		if valuesFrom[i].Kind == kind {
+			names = append(names, valuesFrom[i].Name)
+		}
+	}
+
+	return names
+}
+
+// sortedUniqueNames returns the sorted and deduplicated non-empty names.
+func sortedUniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
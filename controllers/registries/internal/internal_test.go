@@ -2,10 +2,24 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/strfmt"
+	helmclient "github.com/mittwald/go-helm-client"
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
 	registriestesting "github.com/mittwald/harbor-operator/controllers/registries/testing"
 	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -26,3 +40,1013 @@ func TestBuildClient(t *testing.T) {
 		assert.Errorf(t, err, "could not find key HARBOR_ADMIN_PASSWORD in secret , namespace")
 	}
 }
+
+func TestToComponentStatuses(t *testing.T) {
+	now := metav1.Now()
+
+	statuses := ToComponentStatuses([]*model.ComponentHealthStatus{
+		{Name: "core", Status: HealthStatusHealthy},
+		nil,
+		{Name: "registry", Status: "unhealthy", Error: "connection refused"},
+	}, now)
+
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, "core", statuses[0].Name)
+		assert.Equal(t, HealthStatusHealthy, statuses[0].Status)
+		assert.Equal(t, "registry", statuses[1].Name)
+		assert.Equal(t, "connection refused", statuses[1].Error)
+		assert.Equal(t, now, statuses[1].LastCheckTime)
+	}
+}
+
+func TestAdminCredentialsSecretName(t *testing.T) {
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+
+	assert.Equal(t, "test-harbor-harbor-core", AdminCredentialsSecretName(harbor))
+
+	harbor.Spec.AdminCredentials = &v1alpha2.InstanceAdminCredentials{
+		SecretRef: &corev1.LocalObjectReference{Name: "external-harbor-admin"},
+	}
+
+	assert.Equal(t, "external-harbor-admin", AdminCredentialsSecretName(harbor))
+}
+
+func TestGetAdminCredentials(t *testing.T) {
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	adminSecret := registriestesting.CreateSecret("harbor-admin", ns)
+	adminSecret.Data = map[string][]byte{
+		"username": []byte("harbor-admin"),
+		"password": []byte("secret"),
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret, &adminSecret).Build()
+
+	t.Run("DefaultCredentials", func(t *testing.T) {
+		username, password, err := GetAdminCredentials(ctx, fakeClient, harbor)
+		if assert.NoError(t, err) {
+			assert.Equal(t, DefaultAdminUsername, username)
+			assert.Equal(t, "test", password)
+		}
+	})
+
+	t.Run("CustomCredentials", func(t *testing.T) {
+		custom := harbor.DeepCopy()
+		custom.Spec.AdminCredentials = &v1alpha2.InstanceAdminCredentials{
+			SecretRef:   &corev1.LocalObjectReference{Name: "harbor-admin"},
+			UsernameKey: "username",
+			PasswordKey: "password",
+		}
+
+		username, password, err := GetAdminCredentials(ctx, fakeClient, custom)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "harbor-admin", username)
+			assert.Equal(t, "secret", password)
+		}
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		custom := harbor.DeepCopy()
+		custom.Spec.AdminCredentials = &v1alpha2.InstanceAdminCredentials{
+			PasswordKey: "password",
+		}
+
+		_, _, err := GetAdminCredentials(ctx, fakeClient, custom)
+		assert.Error(t, err)
+	})
+}
+
+func TestClientOptions(t *testing.T) {
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+
+	opts := clientOptions(harbor)
+	assert.Equal(t, defaultClientTimeout, opts.Timeout)
+	assert.Equal(t, int64(defaultClientPageSize), opts.PageSize)
+
+	harbor.Spec.APIClient = &v1alpha2.InstanceAPIClientSpec{
+		Timeout:  &metav1.Duration{Duration: time.Minute},
+		PageSize: 100,
+	}
+
+	opts = clientOptions(harbor)
+	assert.Equal(t, time.Minute, opts.Timeout)
+	assert.Equal(t, int64(100), opts.PageSize)
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	ctx := context.TODO()
+
+	caBundle := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "harbor-ca", Namespace: ns},
+		Data:       map[string]string{"ca.crt": "not a certificate"},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&caBundle).Build()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+
+	t.Run("Default", func(t *testing.T) {
+		tlsConfig, err := buildTLSConfig(ctx, fakeClient, harbor)
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("InsecureSkipVerify", func(t *testing.T) {
+		insecure := harbor.DeepCopy()
+		insecure.Spec.APIClient = &v1alpha2.InstanceAPIClientSpec{InsecureSkipVerify: true}
+
+		tlsConfig, err := buildTLSConfig(ctx, fakeClient, insecure)
+		if assert.NoError(t, err) && assert.NotNil(t, tlsConfig) {
+			assert.True(t, tlsConfig.InsecureSkipVerify)
+		}
+	})
+
+	t.Run("InvalidCABundle", func(t *testing.T) {
+		invalid := harbor.DeepCopy()
+		invalid.Spec.APIClient = &v1alpha2.InstanceAPIClientSpec{
+			CABundle: &v1alpha2.InstanceCABundleSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "harbor-ca"},
+					Key:                  "ca.crt",
+				},
+			},
+		}
+
+		_, err := buildTLSConfig(ctx, fakeClient, invalid)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingOptionalCABundle", func(t *testing.T) {
+		optional := true
+		missing := harbor.DeepCopy()
+		missing.Spec.APIClient = &v1alpha2.InstanceAPIClientSpec{
+			CABundle: &v1alpha2.InstanceCABundleSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+					Key:                  "ca.crt",
+					Optional:             &optional,
+				},
+			},
+		}
+
+		tlsConfig, err := buildTLSConfig(ctx, fakeClient, missing)
+		if assert.NoError(t, err) && assert.NotNil(t, tlsConfig) {
+			assert.Nil(t, tlsConfig.RootCAs)
+		}
+	})
+}
+
+func TestDiffConfiguration(t *testing.T) {
+	current := &model.ConfigurationsResponse{
+		AuthMode:                   &model.StringConfigItem{Editable: false, Value: "db_auth"},
+		ProjectCreationRestriction: &model.StringConfigItem{Editable: true, Value: "everyone"},
+		ReadOnly:                   &model.BoolConfigItem{Editable: true, Value: false},
+		RobotTokenDuration:         &model.IntegerConfigItem{Editable: true, Value: 30},
+		SelfRegistration:           &model.BoolConfigItem{Editable: true, Value: false},
+	}
+
+	desired := map[string]apiextensionsv1.JSON{
+		"auth_mode":                    {Raw: []byte(`"oidc_auth"`)},
+		"project_creation_restriction": {Raw: []byte(`"adminonly"`)},
+		"read_only":                    {Raw: []byte(`false`)},
+		"robot_token_duration":         {Raw: []byte(`90`)},
+		"self_registration":            {Raw: []byte(`"yes"`)},
+		"unknown_item":                 {Raw: []byte(`true`)},
+	}
+
+	update, rejected, err := DiffConfiguration(desired, current)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"project_creation_restriction": "adminonly",
+		"robot_token_duration":         float64(90),
+	}, update)
+
+	if assert.Len(t, rejected, 3) {
+		assert.Equal(t, "auth_mode", rejected[0].Key)
+		assert.Equal(t, "self_registration", rejected[1].Key)
+		assert.Equal(t, "unknown_item", rejected[2].Key)
+	}
+
+	cfg, err := ToConfigurations(update)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "adminonly", *cfg.ProjectCreationRestriction)
+		assert.Equal(t, int64(90), *cfg.RobotTokenDuration)
+		assert.Nil(t, cfg.ReadOnly)
+	}
+}
+
+func TestOIDCConfigurations(t *testing.T) {
+	spec := &v1alpha2.InstanceOIDCSpec{
+		Name:        "keycloak",
+		Endpoint:    "https://keycloak.example.com/realms/harbor",
+		ClientID:    "harbor",
+		GroupsClaim: "groups",
+		AdminGroup:  "harbor-admins",
+		ExtraRedirectParams: map[string]string{
+			"prompt": "login",
+		},
+	}
+
+	cfg, err := OIDCConfigurations(spec, "client-secret")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, AuthModeOIDC, *cfg.AuthMode)
+	assert.Equal(t, "client-secret", *cfg.OIDCClientSecret)
+	assert.Equal(t, "openid,offline_access", *cfg.OIDCScope)
+	assert.Equal(t, `{"prompt":"login"}`, *cfg.OIDCExtraRedirectParms)
+	assert.True(t, *cfg.OIDCVerifyCert)
+
+	values, err := ConfigurationValues(cfg, OIDCClientSecretKey)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, values, OIDCClientSecretKey)
+		assert.Equal(t, `"harbor"`, string(values["oidc_client_id"].Raw))
+	}
+}
+
+func TestConfigurationHash(t *testing.T) {
+	spec := &v1alpha2.InstanceOIDCSpec{
+		Name:     "keycloak",
+		Endpoint: "https://keycloak.example.com/realms/harbor",
+		ClientID: "harbor",
+	}
+
+	hashOf := func(clientSecret, secretVersion string) string {
+		cfg, err := OIDCConfigurations(spec, clientSecret)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+
+		values, err := ConfigurationValues(cfg, OIDCClientSecretKey)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+
+		hash, err := ConfigurationHash(values, secretVersion)
+		assert.NoError(t, err)
+
+		return hash
+	}
+
+	hash := hashOf("client-secret", "1")
+
+	// The hash must not be derived from the client secret, only from the version of the secret holding it.
+	assert.Equal(t, hash, hashOf("other-client-secret", "1"))
+	assert.NotEqual(t, hash, hashOf("client-secret", "2"))
+
+	spec.ClientID = "other"
+	assert.NotEqual(t, hash, hashOf("client-secret", "1"))
+}
+
+func TestLDAPConfigurations(t *testing.T) {
+	verifyCert := false
+	spec := &v1alpha2.InstanceLDAPSpec{
+		URL:        "ldaps://ldap.example.com",
+		BaseDN:     "dc=example,dc=com",
+		BindDN:     "cn=harbor,dc=example,dc=com",
+		Scope:      v1alpha2.LDAPSearchScopeOneLevel,
+		VerifyCert: &verifyCert,
+	}
+
+	cfg := LDAPConfigurations(spec, "bind-password")
+
+	assert.Equal(t, AuthModeLDAP, *cfg.AuthMode)
+	assert.Equal(t, "bind-password", *cfg.LdapSearchPassword)
+	assert.Equal(t, "uid", *cfg.LdapUID)
+	assert.Equal(t, int64(1), *cfg.LdapScope)
+	assert.Equal(t, int64(2), *cfg.LdapGroupSearchScope)
+	assert.Equal(t, "memberof", *cfg.LdapGroupMembershipAttribute)
+	assert.False(t, *cfg.LdapVerifyCert)
+
+	values, err := ConfigurationValues(cfg, LDAPSearchPasswordKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NotContains(t, values, LDAPSearchPasswordKey)
+
+	// The hash of the applied settings must not be derived from the bind password.
+	otherValues, err := ConfigurationValues(LDAPConfigurations(spec, "other-bind-password"), LDAPSearchPasswordKey)
+	if assert.NoError(t, err) {
+		hash, err := ConfigurationHash(values, "1")
+		assert.NoError(t, err)
+
+		otherHash, err := ConfigurationHash(otherValues, "1")
+		assert.NoError(t, err)
+		assert.Equal(t, hash, otherHash)
+	}
+}
+
+func TestAPIClient_PingLDAP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+
+		switch {
+		case r.URL.Path != "/api/v2.0/ldap/ping" || r.Method != http.MethodPost:
+			w.WriteHeader(http.StatusNotFound)
+		case username != "admin" || password != "test":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"success": false, "message": "failed to connect"}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	harbor.Spec.InstanceURL = server.URL
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret).Build()
+
+	apiClient, err := BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := apiClient.PingLDAP(ctx, &model.LdapConf{LdapURL: "ldaps://ldap.example.com"})
+	if assert.NoError(t, err) {
+		assert.False(t, result.Success)
+		assert.Equal(t, "failed to connect", result.Message)
+	}
+
+	harbor.Spec.InstanceURL = server.URL + "/unknown"
+
+	apiClient, err = BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = apiClient.PingLDAP(ctx, &model.LdapConf{})
+
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	}
+}
+
+func TestPartitionCVEAllowlist(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := metav1.NewTime(now.Add(-time.Hour))
+	future := metav1.NewTime(now.Add(time.Hour))
+
+	active, expired := PartitionCVEAllowlist(nil, now)
+	assert.Empty(t, active)
+	assert.Empty(t, expired)
+
+	active, expired = PartitionCVEAllowlist(&v1alpha2.CVEAllowlist{
+		Items: []v1alpha2.CVEAllowlistItem{
+			{ID: "CVE-2021-0002"},
+			{ID: "CVE-2021-0001", ExpiresAt: &future},
+			{ID: "CVE-2021-0003", ExpiresAt: &past},
+			{ID: "CVE-2021-0002", ExpiresAt: &past},
+		},
+	}, now)
+
+	assert.Equal(t, []string{"CVE-2021-0001", "CVE-2021-0002"}, active)
+	assert.Equal(t, []v1alpha2.CVEAllowlistItem{{ID: "CVE-2021-0003", ExpiresAt: &past}}, expired)
+}
+
+func TestCVEAllowlistIDs(t *testing.T) {
+	assert.Empty(t, CVEAllowlistIDs(nil))
+
+	ids := CVEAllowlistIDs(&model.CVEAllowlist{
+		Items: ToCVEAllowlistItems([]string{"CVE-2021-0002", "CVE-2021-0001", "CVE-2021-0002"}),
+	})
+
+	assert.Equal(t, []string{"CVE-2021-0001", "CVE-2021-0002"}, ids)
+}
+
+func TestAPIClient_SystemCVEAllowlist(t *testing.T) {
+	var updated model.CVEAllowlist
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/api/v2.0/system/CVEAllowlist":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": 1, "project_id": 0, "items": [{"cve_id": "CVE-2021-0001"}]}`))
+		case r.Method == http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	harbor.Spec.InstanceURL = server.URL
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret).Build()
+
+	apiClient, err := BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	allowlist, err := apiClient.GetSystemCVEAllowlist(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"CVE-2021-0001"}, CVEAllowlistIDs(allowlist))
+	}
+
+	err = apiClient.UpdateSystemCVEAllowlist(ctx, &model.CVEAllowlist{
+		Items: ToCVEAllowlistItems([]string{"CVE-2021-0002"}),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"CVE-2021-0002"}, CVEAllowlistIDs(&updated))
+		assert.Nil(t, updated.ExpiresAt)
+	}
+}
+
+func TestNextCVEAllowlistExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := metav1.NewTime(now.Add(-time.Hour))
+	soon := metav1.NewTime(now.Add(time.Minute))
+	later := metav1.NewTime(now.Add(time.Hour))
+
+	assert.Zero(t, NextCVEAllowlistExpiry(nil, now))
+
+	assert.Equal(t, time.Minute, NextCVEAllowlistExpiry(&v1alpha2.CVEAllowlist{
+		Items: []v1alpha2.CVEAllowlistItem{
+			{ID: "CVE-2021-0001"},
+			{ID: "CVE-2021-0002", ExpiresAt: &later},
+			{ID: "CVE-2021-0003", ExpiresAt: &past},
+			{ID: "CVE-2021-0004", ExpiresAt: &soon},
+		},
+	}, now))
+}
+
+func TestCVEAllowlistMatches(t *testing.T) {
+	expiresAt := int64(1704067200)
+	ids := []string{"CVE-2021-0001", "CVE-2021-0002"}
+
+	assert.True(t, CVEAllowlistMatches(nil, nil))
+	assert.False(t, CVEAllowlistMatches(nil, ids))
+	assert.True(t, CVEAllowlistMatches(&model.CVEAllowlist{Items: ToCVEAllowlistItems(ids)}, ids))
+	assert.False(t, CVEAllowlistMatches(&model.CVEAllowlist{Items: ToCVEAllowlistItems(ids[:1])}, ids))
+	assert.False(t, CVEAllowlistMatches(&model.CVEAllowlist{
+		Items:     ToCVEAllowlistItems(ids),
+		ExpiresAt: &expiresAt,
+	}, ids))
+}
+
+func TestGenerateProjectCVEAllowlist(t *testing.T) {
+	allowlist := GenerateProjectCVEAllowlist(&model.Project{
+		ProjectID:    3,
+		CVEAllowlist: &model.CVEAllowlist{ID: 7, ProjectID: 3},
+	}, []string{"CVE-2021-0001"})
+
+	assert.Equal(t, int64(7), allowlist.ID)
+	assert.Equal(t, int64(3), allowlist.ProjectID)
+	assert.Equal(t, []string{"CVE-2021-0001"}, CVEAllowlistIDs(allowlist))
+	assert.Nil(t, allowlist.ExpiresAt)
+}
+
+func TestAPIClient_Scanners(t *testing.T) {
+	var (
+		created   model.ScannerRegistrationReq
+		isDefault bool
+		deleted   bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v2.0/scanners" && r.Method == http.MethodGet:
+			if r.URL.Query().Get("page") != "1" {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`[{"uuid": "abc", "name": "trivy", "url": "http://trivy:8080", "is_default": false}]`))
+		case r.URL.Path == "/api/v2.0/scanners" && r.Method == http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/api/v2.0/scanners/abc" && r.Method == http.MethodPatch:
+			var body map[string]bool
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			isDefault = body["is_default"]
+		case r.URL.Path == "/api/v2.0/scanners/abc" && r.Method == http.MethodDelete:
+			deleted = true
+		case r.URL.Path == "/api/v2.0/scanners/abc/metadata" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"scanner": {"name": "Trivy", "vendor": "Aqua Security", "version": "v0.50.0"},
+				"capabilities": [{"consumes_mime_types": ["application/vnd.oci.image.manifest.v1+json"],
+				"produces_mime_types": ["application/vnd.security.vulnerability.report; version=1.1"]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	harbor.Spec.InstanceURL = server.URL
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret).Build()
+
+	apiClient, err := BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	registration, err := apiClient.GetScannerRegistrationByName(ctx, "trivy")
+	if assert.NoError(t, err) && assert.NotNil(t, registration) {
+		assert.Equal(t, "abc", registration.UUID)
+	}
+
+	registration, err = apiClient.GetScannerRegistrationByName(ctx, "clair")
+	assert.NoError(t, err)
+	assert.Nil(t, registration)
+
+	spec := registriestesting.CreateScanner("test-scanner", ns, harbor.Name).Spec
+	spec.Auth = &v1alpha2.ScannerAuth{Type: v1alpha2.ScannerAuthTypeBearer}
+
+	assert.NoError(t, apiClient.NewScannerRegistration(ctx, ToScannerRegistrationReq(&spec, "token")))
+	assert.Equal(t, "test-scanner", *created.Name)
+	assert.Equal(t, "Bearer", created.Auth)
+	assert.Equal(t, "token", created.AccessCredential)
+
+	assert.NoError(t, apiClient.SetDefaultScannerRegistration(ctx, "abc"))
+	assert.True(t, isDefault)
+
+	metadata, err := apiClient.GetScannerMetadata(ctx, "abc")
+	if assert.NoError(t, err) {
+		status := ToScannerMetadata(metadata)
+		assert.Equal(t, "Trivy", status.Name)
+		assert.Equal(t, "Aqua Security", status.Vendor)
+		assert.Len(t, status.Capabilities, 1)
+	}
+
+	assert.NoError(t, apiClient.DeleteScannerRegistration(ctx, "abc"))
+	assert.True(t, deleted)
+}
+
+func TestScannerRegistrationMatches(t *testing.T) {
+	spec := registriestesting.CreateScanner("test-scanner", ns, "test-harbor").Spec
+	req := ToScannerRegistrationReq(&spec, "")
+
+	held := &model.ScannerRegistration{
+		Name:        spec.Name,
+		Description: spec.Description,
+		URL:         *req.URL,
+	}
+
+	assert.True(t, ScannerRegistrationMatches(held, req))
+
+	spec.SkipCertVerify = true
+	assert.False(t, ScannerRegistrationMatches(held, ToScannerRegistrationReq(&spec, "")))
+
+	spec.SkipCertVerify = false
+	spec.URL = "http://clair:8080"
+	assert.False(t, ScannerRegistrationMatches(held, ToScannerRegistrationReq(&spec, "")))
+}
+
+func TestScannerRegistrationHash(t *testing.T) {
+	spec := registriestesting.CreateScanner("test-scanner", ns, "test-harbor").Spec
+	spec.Auth = &v1alpha2.ScannerAuth{Type: v1alpha2.ScannerAuthTypeBearer}
+
+	req := ToScannerRegistrationReq(&spec, "credential")
+
+	hash, err := ScannerRegistrationHash(req, "1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The hash must not be derived from the access credential, only from the version of the secret holding it.
+	otherHash, err := ScannerRegistrationHash(ToScannerRegistrationReq(&spec, "other-credential"), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, hash, otherHash)
+
+	rotatedHash, err := ScannerRegistrationHash(req, "2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, rotatedHash)
+
+	assert.Equal(t, "credential", req.AccessCredential)
+}
+
+func TestAPIClient_GetScanAllMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v2.0/scans/all/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ongoing": true, "trigger": "Schedule", "total": 10, "completed": 4,
+			"metrics": {"Success": 3, "Error": 1}}`))
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	harbor.Spec.InstanceURL = server.URL
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret).Build()
+
+	apiClient, err := BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stats, err := apiClient.GetScanAllMetrics(ctx)
+	if assert.NoError(t, err) {
+		assert.True(t, stats.Ongoing)
+		assert.Equal(t, "Schedule", stats.Trigger)
+		assert.Equal(t, int64(10), stats.Total)
+		assert.Equal(t, int64(4), stats.Completed)
+		assert.Equal(t, map[string]int64{"Success": 3, "Error": 1}, stats.Metrics)
+	}
+}
+
+func TestToScanAllStatus(t *testing.T) {
+	checkTime := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, ToScanAllStatus(nil, checkTime))
+
+	status := ToScanAllStatus(&model.Stats{
+		Ongoing:   false,
+		Trigger:   "Manual",
+		Total:     2,
+		Completed: 2,
+		Metrics:   map[string]int64{"Success": 2},
+	}, checkTime)
+
+	assert.Equal(t, &v1alpha2.InstanceScanAllStatus{
+		Trigger:       "Manual",
+		Total:         2,
+		Completed:     2,
+		Metrics:       map[string]int64{"Success": 2},
+		LastCheckTime: checkTime,
+	}, status)
+}
+
+func TestAuditLogPurgeOperations(t *testing.T) {
+	assert.Equal(t, "create,delete,pull", AuditLogPurgeOperations(nil))
+	assert.Equal(t, "delete,pull", AuditLogPurgeOperations([]v1alpha2.AuditLogOperation{
+		v1alpha2.AuditLogOperationPull,
+		v1alpha2.AuditLogOperationDelete,
+		v1alpha2.AuditLogOperationPull,
+	}))
+}
+
+func TestAuditLogPurgeScheduleMatches(t *testing.T) {
+	desired := ToAuditLogPurgeSchedule(&v1alpha2.AuditLogPurge{
+		Cron:              "0 0 * * 0",
+		RetentionHours:    720,
+		IncludeOperations: []v1alpha2.AuditLogOperation{v1alpha2.AuditLogOperationPull},
+	}, v1alpha2.ScheduleTypeWeekly)
+
+	held := func(cron, params string) *model.ExecHistory {
+		return &model.ExecHistory{
+			Schedule:      &model.ScheduleObj{Cron: cron, Type: string(v1alpha2.ScheduleTypeWeekly)},
+			JobParameters: params,
+		}
+	}
+
+	assert.True(t, AuditLogPurgeScheduleMatches(
+		held("0 0 * * 0", `{"audit_retention_hour": 720, "include_operations": "pull", "dry_run": false}`), desired))
+	assert.False(t, AuditLogPurgeScheduleMatches(
+		held("0 0 * * 1", `{"audit_retention_hour": 720, "include_operations": "pull"}`), desired))
+	assert.False(t, AuditLogPurgeScheduleMatches(
+		held("0 0 * * 0", `{"audit_retention_hour": 24, "include_operations": "pull"}`), desired))
+	assert.False(t, AuditLogPurgeScheduleMatches(
+		held("0 0 * * 0", `{"audit_retention_hour": 720, "include_operations": "create,pull"}`), desired))
+	assert.False(t, AuditLogPurgeScheduleMatches(held("0 0 * * 0", `invalid`), desired))
+	assert.False(t, AuditLogPurgeScheduleMatches(&model.ExecHistory{}, desired))
+}
+
+func TestAPIClient_GarbageCollectionExecutions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodGet:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/api/v2.0/system/gc" && r.URL.Query().Get("page_size") == "2":
+			_, _ = w.Write([]byte(`[
+				{"id": 2, "job_status": "Running", "creation_time": "2024-01-02T00:00:00Z"},
+				{"id": 1, "job_status": "Success", "creation_time": "2024-01-01T00:00:00Z",
+					"update_time": "2024-01-01T00:10:00Z"}
+			]`))
+		case r.URL.Path == "/api/v2.0/system/gc/1/log" && r.Header.Get("Accept") == "text/plain":
+			_, _ = w.Write([]byte("2024-01-01T00:10:00Z [INFO] The GC job actual frees up 34 MB space.\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+	harbor.Spec.InstanceURL = server.URL
+	coreSecret := registriestesting.CreateSecret(harbor.Name+"-harbor-core", ns)
+
+	fakeClient := fake.NewClientBuilder().WithObjects(&coreSecret).Build()
+
+	apiClient, err := BuildAPIClient(ctx, fakeClient, harbor)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	executions, err := apiClient.ListGarbageCollectionExecutions(ctx, 2)
+	if assert.NoError(t, err) && assert.Len(t, executions, 2) {
+		assert.Equal(t, int64(2), executions[0].ID)
+		assert.Equal(t, "Success", executions[1].JobStatus)
+	}
+
+	log, err := apiClient.GetGarbageCollectionLog(ctx, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "34Mi", ParseGarbageCollectionFreedSpace(log).String())
+	}
+}
+
+func TestToGarbageCollectionExecution(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	running := ToGarbageCollectionExecution(&model.GCHistory{
+		ID:           2,
+		JobStatus:    "Running",
+		CreationTime: strfmt.DateTime(start),
+		UpdateTime:   strfmt.DateTime(end),
+	})
+	assert.Equal(t, int64(2), running.ID)
+	assert.Equal(t, "Running", running.Status)
+	assert.True(t, running.StartTime.Time.Equal(start))
+	assert.Nil(t, running.EndTime)
+
+	finished := ToGarbageCollectionExecution(&model.GCHistory{
+		ID:           1,
+		JobStatus:    "Success",
+		CreationTime: strfmt.DateTime(start),
+		UpdateTime:   strfmt.DateTime(end),
+	})
+	if assert.NotNil(t, finished.EndTime) {
+		assert.True(t, finished.EndTime.Time.Equal(end))
+	}
+
+	assert.Nil(t, ToGarbageCollectionExecution(&model.GCHistory{ID: 3}).StartTime)
+}
+
+func TestParseGarbageCollectionFreedSpace(t *testing.T) {
+	assert.Nil(t, ParseGarbageCollectionFreedSpace("no space reported"))
+	assert.Equal(t, "0", ParseGarbageCollectionFreedSpace("The GC job actual frees up 0 MB space.").String())
+	assert.Equal(t, "1Gi", ParseGarbageCollectionFreedSpace("The GC job actual frees up 1024 MB space.").String())
+}
+
+func TestGarbageCollectionParameters(t *testing.T) {
+	workers := int32(3)
+
+	assert.Equal(t, map[string]interface{}{"delete_untagged": false, "dry_run": false},
+		GarbageCollectionParameters(nil))
+	assert.Equal(t, map[string]interface{}{"delete_untagged": true, "dry_run": true, "workers": int32(3)},
+		GarbageCollectionParameters(&v1alpha2.GarbageCollection{DeleteUntagged: true, DryRun: true, Workers: &workers}))
+}
+
+func TestGarbageCollectionScheduleMatches(t *testing.T) {
+	workers := int32(2)
+
+	desired := ToGarbageCollectionSchedule(&v1alpha2.GarbageCollection{
+		Cron:           "0 0 * * *",
+		DeleteUntagged: true,
+		Workers:        &workers,
+	}, v1alpha2.ScheduleTypeDaily)
+
+	held := func(cron, params string) *model.GCHistory {
+		return &model.GCHistory{
+			Schedule: &model.ScheduleObj{
+				Cron:              cron,
+				Type:              string(v1alpha2.ScheduleTypeDaily),
+				NextScheduledTime: strfmt.DateTime(time.Now()),
+			},
+			JobParameters: params,
+		}
+	}
+
+	assert.True(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": true, "dry_run": false, "workers": 2}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(
+		held("0 1 * * *", `{"delete_untagged": true, "dry_run": false, "workers": 2}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": false, "dry_run": false, "workers": 2}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": true, "dry_run": true, "workers": 2}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": true, "dry_run": false, "workers": 4}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": true, "dry_run": false}`), desired))
+	assert.False(t, GarbageCollectionScheduleMatches(held("0 0 * * *", `invalid`), desired))
+
+	// The number of workers is left to Harbor if not set.
+	desired = ToGarbageCollectionSchedule(&v1alpha2.GarbageCollection{Cron: "0 0 * * *"}, v1alpha2.ScheduleTypeDaily)
+	assert.True(t, GarbageCollectionScheduleMatches(
+		held("0 0 * * *", `{"delete_untagged": false, "dry_run": false, "workers": 4}`), desired))
+}
+
+func TestToGarbageCollectionExecution_DryRun(t *testing.T) {
+	execution := ToGarbageCollectionExecution(&model.GCHistory{
+		ID:            1,
+		JobStatus:     "Success",
+		JobParameters: `{"delete_untagged": false, "dry_run": true}`,
+	})
+	assert.True(t, execution.DryRun)
+
+	assert.Equal(t, "12Mi", ParseGarbageCollectionFreedSpace(
+		"The GC could free up 12 MB space, the size is a rough estimate.").String())
+}
+
+func TestInstanceReferences(t *testing.T) {
+	harbor := registriestesting.CreateInstance("test-harbor", ns)
+
+	assert.Equal(t, []string{"test-harbor-harbor-core"}, InstanceSecretNames(harbor))
+	assert.Empty(t, InstanceConfigMapNames(harbor))
+
+	harbor.Spec.HelmChart.SecretValues = &v1alpha2.InstanceHelmChartSecretValues{
+		SecretRef: &corev1.LocalObjectReference{Name: "values"},
+		Key:       "values.yaml",
+	}
+	harbor.Spec.HelmChart.ValuesFrom = []v1alpha2.InstanceHelmChartValuesReference{
+		{Kind: "ConfigMap", Name: "defaults"},
+		{Kind: "Secret", Name: "database", Key: "password", TargetPath: "database.external.password"},
+		{Kind: "Secret", Name: "values"},
+	}
+	harbor.Spec.HelmChart.Patches = []v1alpha2.InstanceHelmChartPatch{
+		{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "patches"},
+			Key:                  "patch.yaml",
+		}},
+	}
+	harbor.Spec.APIClient = &v1alpha2.InstanceAPIClientSpec{
+		CABundle: &v1alpha2.InstanceCABundleSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"},
+				Key:                  "ca.crt",
+			},
+		},
+	}
+	harbor.Spec.OIDC = &v1alpha2.InstanceOIDCSpec{
+		ClientSecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "oidc"},
+			Key:                  "secret",
+		},
+	}
+
+	assert.Equal(t, []string{"database", "oidc", "test-harbor-harbor-core", "values"}, InstanceSecretNames(harbor))
+
+	harbor.Spec.AdminCredentials = &v1alpha2.InstanceAdminCredentials{
+		SecretRef: &corev1.LocalObjectReference{Name: "admin"},
+	}
+
+	assert.Equal(t, []string{"admin", "database", "oidc", "values"}, InstanceSecretNames(harbor))
+	assert.Equal(t, []string{"ca-bundle", "defaults", "patches"}, InstanceConfigMapNames(harbor))
+}
+
+func TestHelmReleaseDrift(t *testing.T) {
+	spec := &helmclient.ChartSpec{ReleaseName: "test-harbor", Version: "1.14.2"}
+	values := map[string]interface{}{"expose": map[string]interface{}{"type": "ingress"}, "replicas": 2}
+
+	deployedRelease := func() *release.Release {
+		return &release.Release{
+			Version: 3,
+			Info:    &release.Info{Status: release.StatusDeployed},
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Name: "harbor", Version: "1.14.2"}},
+			Config:  map[string]interface{}{"expose": map[string]interface{}{"type": "ingress"}, "replicas": float64(2)},
+		}
+	}
+
+	drift, err := HelmReleaseDrift(deployedRelease(), spec, values, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	drift, err = HelmReleaseDrift(nil, spec, values, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "helm release does not exist", drift)
+
+	rel := deployedRelease()
+	rel.Info.Status = release.StatusPendingUpgrade
+	drift, err = HelmReleaseDrift(rel, spec, values, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	rel = deployedRelease()
+	rel.Info.Status = release.StatusFailed
+	drift, err = HelmReleaseDrift(rel, spec, values, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, `helm release is in status "failed"`, drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), spec, values, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "helm release revision changed from 2 to 3", drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), spec, values, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), &helmclient.ChartSpec{Version: "1.15.0"}, values, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, `chart version "1.14.2" differs from the desired version "1.15.0"`, drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), &helmclient.ChartSpec{Version: "v1.14.2"}, values, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), &helmclient.ChartSpec{Version: "~1.15"}, values, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	drift, err = HelmReleaseDrift(deployedRelease(), spec, map[string]interface{}{"replicas": 2}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "helm release values differ from the desired values", drift)
+
+	rel = deployedRelease()
+	rel.Config = nil
+	drift, err = HelmReleaseDrift(rel, spec, map[string]interface{}{}, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+}
+
+func TestToHelmReleaseStatus(t *testing.T) {
+	assert.Nil(t, ToHelmReleaseStatus(nil))
+
+	lastDeployed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	status := ToHelmReleaseStatus(&release.Release{
+		Name:      "test-harbor",
+		Namespace: ns,
+		Version:   4,
+		Info: &release.Info{
+			Status:       release.StatusPendingUpgrade,
+			LastDeployed: helmtime.Time{Time: lastDeployed},
+		},
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "harbor", Version: "1.14.2", AppVersion: "2.10.2"}},
+	})
+
+	assert.Equal(t, &v1alpha2.InstanceHelmReleaseStatus{
+		Name:         "test-harbor",
+		Namespace:    ns,
+		Revision:     4,
+		Chart:        "harbor",
+		ChartVersion: "1.14.2",
+		AppVersion:   "2.10.2",
+		LastDeployed: &metav1.Time{Time: lastDeployed},
+		Status:       "pending-upgrade",
+	}, status)
+
+	status = ToHelmReleaseStatus(&release.Release{Name: "test-harbor", Namespace: ns, Version: 1})
+	assert.Equal(t, &v1alpha2.InstanceHelmReleaseStatus{Name: "test-harbor", Namespace: ns, Revision: 1}, status)
+}
+
+func TestIsHelmReleaseStuck(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	pendingRelease := func(status release.Status, lastDeployed time.Time) *release.Release {
+		return &release.Release{
+			Version: 2,
+			Info:    &release.Info{Status: status, LastDeployed: helmtime.Time{Time: lastDeployed}},
+		}
+	}
+
+	assert.False(t, IsHelmReleaseStuck(nil, 15*time.Minute, now))
+	assert.False(t, IsHelmReleaseStuck(pendingRelease(release.StatusDeployed, now.Add(-time.Hour)), 15*time.Minute, now))
+	assert.False(t, IsHelmReleaseStuck(pendingRelease(release.StatusPendingUpgrade, now.Add(-10*time.Minute)),
+		15*time.Minute, now))
+	assert.True(t, IsHelmReleaseStuck(pendingRelease(release.StatusPendingUpgrade, now.Add(-20*time.Minute)),
+		15*time.Minute, now))
+	assert.True(t, IsHelmReleaseStuck(pendingRelease(release.StatusPendingInstall, now.Add(-20*time.Minute)),
+		15*time.Minute, now))
+}
+
+func TestCanRollbackHelmRelease(t *testing.T) {
+	revision := func(version int, status release.Status) *release.Release {
+		return &release.Release{Version: version, Info: &release.Info{Status: status}}
+	}
+
+	stuck := revision(3, release.StatusPendingUpgrade)
+
+	assert.True(t, CanRollbackHelmRelease(stuck, []*release.Release{
+		revision(1, release.StatusSuperseded), revision(2, release.StatusDeployed), stuck,
+	}))
+	assert.True(t, CanRollbackHelmRelease(stuck, []*release.Release{
+		revision(2, release.StatusSuperseded), stuck,
+	}))
+	assert.False(t, CanRollbackHelmRelease(stuck, []*release.Release{
+		revision(1, release.StatusDeployed), revision(2, release.StatusFailed), stuck,
+	}))
+	assert.False(t, CanRollbackHelmRelease(revision(1, release.StatusPendingInstall), []*release.Release{
+		revision(1, release.StatusPendingInstall),
+	}))
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+const (
+	// AuthModeLDAP is the Harbor auth mode authenticating users against an LDAP server.
+	AuthModeLDAP = "ldap_auth"
+
+	// LDAPSearchPasswordKey is the system configuration item holding the LDAP bind password.
+	// It is never returned by the Harbor configurations API.
+	LDAPSearchPasswordKey = "ldap_search_password"
+
+	defaultLDAPUID                      = "uid"
+	defaultLDAPGroupMembershipAttribute = "memberof"
+)
+
+// LDAPConfigurations returns the system configuration items enabling the LDAP authentication of a harbor instance.
+func LDAPConfigurations(spec *v1alpha2.InstanceLDAPSpec, bindPassword string) *model.Configurations {
+	conf := LDAPConf(spec, bindPassword)
+	authMode := AuthModeLDAP
+	groupScope := ldapScope(spec.GroupScope)
+
+	groupMembershipAttribute := spec.GroupMembershipAttribute
+	if groupMembershipAttribute == "" {
+		groupMembershipAttribute = defaultLDAPGroupMembershipAttribute
+	}
+
+	return &model.Configurations{
+		AuthMode:                     &authMode,
+		LdapURL:                      &conf.LdapURL,
+		LdapBaseDn:                   &conf.LdapBaseDn,
+		LdapFilter:                   &conf.LdapFilter,
+		LdapUID:                      &conf.LdapUID,
+		LdapScope:                    &conf.LdapScope,
+		LdapSearchDn:                 &conf.LdapSearchDn,
+		LdapSearchPassword:           &conf.LdapSearchPassword,
+		LdapVerifyCert:               &conf.LdapVerifyCert,
+		LdapGroupBaseDn:              &spec.GroupBaseDN,
+		LdapGroupSearchFilter:        &spec.GroupFilter,
+		LdapGroupAttributeName:       &spec.GroupAttributeName,
+		LdapGroupAdminDn:             &spec.GroupAdminDN,
+		LdapGroupMembershipAttribute: &groupMembershipAttribute,
+		LdapGroupSearchScope:         &groupScope,
+	}
+}
+
+// LDAPConf returns the settings used to test the connection to the LDAP server of a harbor instance.
+func LDAPConf(spec *v1alpha2.InstanceLDAPSpec, bindPassword string) *model.LdapConf {
+	uid := spec.UID
+	if uid == "" {
+		uid = defaultLDAPUID
+	}
+
+	verifyCert := true
+	if spec.VerifyCert != nil {
+		verifyCert = *spec.VerifyCert
+	}
+
+	return &model.LdapConf{
+		LdapURL:            spec.URL,
+		LdapBaseDn:         spec.BaseDN,
+		LdapFilter:         spec.Filter,
+		LdapUID:            uid,
+		LdapScope:          ldapScope(spec.Scope),
+		LdapSearchDn:       spec.BindDN,
+		LdapSearchPassword: bindPassword,
+		LdapVerifyCert:     verifyCert,
+	}
+}
+
+// ldapScope converts an LDAP search scope to the value used by the Harbor API.
+func ldapScope(scope v1alpha2.LDAPSearchScope) int64 {
+	switch scope {
+	case v1alpha2.LDAPSearchScopeBase:
+		return 0
+	case v1alpha2.LDAPSearchScopeOneLevel:
+		return 1
+	default:
+		return 2
+	}
+}
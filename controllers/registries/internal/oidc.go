@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+const (
+	// AuthModeOIDC is the Harbor auth mode authenticating users via an OpenID Connect provider.
+	AuthModeOIDC = "oidc_auth"
+
+	// OIDCClientSecretKey is the system configuration item holding the OIDC client secret.
+	// It is never returned by the Harbor configurations API.
+	OIDCClientSecretKey = "oidc_client_secret"
+)
+
+// defaultOIDCScopes are the scopes requested from the OIDC provider, unless specified otherwise.
+var defaultOIDCScopes = []string{"openid", "offline_access"}
+
+// OIDCConfigurations returns the system configuration items enabling the OIDC authentication of a harbor instance.
+func OIDCConfigurations(spec *v1alpha2.InstanceOIDCSpec, clientSecret string) (*model.Configurations, error) {
+	scopes := spec.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+
+	verifyCert := true
+	if spec.VerifyCert != nil {
+		verifyCert = *spec.VerifyCert
+	}
+
+	extraRedirectParams := "{}"
+
+	if len(spec.ExtraRedirectParams) > 0 {
+		params, err := json.Marshal(spec.ExtraRedirectParams)
+		if err != nil {
+			return nil, err
+		}
+
+		extraRedirectParams = string(params)
+	}
+
+	authMode := AuthModeOIDC
+
+	return &model.Configurations{
+		AuthMode:               &authMode,
+		OIDCName:               &spec.Name,
+		OIDCEndpoint:           &spec.Endpoint,
+		OIDCClientID:           &spec.ClientID,
+		OIDCClientSecret:       &clientSecret,
+		OIDCScope:              stringPtr(strings.Join(scopes, ",")),
+		OIDCGroupsClaim:        &spec.GroupsClaim,
+		OIDCAdminGroup:         &spec.AdminGroup,
+		OIDCGroupFilter:        &spec.GroupFilter,
+		OIDCUserClaim:          &spec.UserClaim,
+		OIDCAutoOnboard:        &spec.AutoOnboard,
+		OIDCVerifyCert:         &verifyCert,
+		OIDCExtraRedirectParms: &extraRedirectParams,
+	}, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -58,3 +58,17 @@ func GenerateProjectMetadata(projectMeta *v1alpha2.ProjectMetadata) *model.Proje
 
 	return &pm
 }
+
+// GenerateProjectCVEAllowlist constructs the CVE allowlist of a Harbor project holding the given CVE IDs.
+func GenerateProjectCVEAllowlist(heldProject *model.Project, ids []string) *model.CVEAllowlist {
+	allowlist := model.CVEAllowlist{
+		ProjectID: int64(heldProject.ProjectID),
+		Items:     ToCVEAllowlistItems(ids),
+	}
+
+	if heldProject.CVEAllowlist != nil {
+		allowlist.ID = heldProject.CVEAllowlist.ID
+	}
+
+	return &allowlist
+}
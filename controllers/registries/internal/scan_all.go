@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+)
+
+// GetScanAllMetrics returns the metrics of the latest execution of the scan all job.
+func (c *APIClient) GetScanAllMetrics(ctx context.Context) (*model.Stats, error) {
+	var stats model.Stats
+
+	if err := c.do(ctx, http.MethodGet, "/scans/all/metrics", nil, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// ToScanAllStatus converts the metrics of the scan all job reported by the Harbor API
+// into the scan all status of an instance, stamped with the time of the check.
+func ToScanAllStatus(stats *model.Stats, checkTime metav1.Time) *v1alpha2.InstanceScanAllStatus {
+	if stats == nil {
+		return nil
+	}
+
+	return &v1alpha2.InstanceScanAllStatus{
+		Ongoing:       stats.Ongoing,
+		Trigger:       stats.Trigger,
+		Total:         stats.Total,
+		Completed:     stats.Completed,
+		Metrics:       stats.Metrics,
+		LastCheckTime: checkTime,
+	}
+}
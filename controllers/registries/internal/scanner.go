@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+)
+
+// scannerPageSize is the page size used when listing scanner registrations.
+const scannerPageSize = 100
+
+// GetScannerRegistrationByName returns the scanner registration with the given name.
+// Returns nil if no such registration exists.
+func (c *APIClient) GetScannerRegistrationByName(ctx context.Context,
+	name string) (*model.ScannerRegistration, error) {
+	for page := 1; ; page++ {
+		var registrations []*model.ScannerRegistration
+
+		path := fmt.Sprintf("/scanners?page=%d&page_size=%d", page, scannerPageSize)
+		if err := c.do(ctx, http.MethodGet, path, nil, &registrations); err != nil {
+			return nil, err
+		}
+
+		for _, registration := range registrations {
+			if registration != nil && registration.Name == name {
+				return registration, nil
+			}
+		}
+
+		if len(registrations) < scannerPageSize {
+			return nil, nil
+		}
+	}
+}
+
+// NewScannerRegistration registers a scanner adapter.
+func (c *APIClient) NewScannerRegistration(ctx context.Context, req *model.ScannerRegistrationReq) error {
+	return c.do(ctx, http.MethodPost, "/scanners", req, nil)
+}
+
+// UpdateScannerRegistration updates the scanner registration with the given UUID.
+func (c *APIClient) UpdateScannerRegistration(ctx context.Context, uuid string,
+	req *model.ScannerRegistrationReq) error {
+	return c.do(ctx, http.MethodPut, "/scanners/"+url.PathEscape(uuid), req, nil)
+}
+
+// SetDefaultScannerRegistration marks the scanner registration with the given UUID as the system default scanner.
+func (c *APIClient) SetDefaultScannerRegistration(ctx context.Context, uuid string) error {
+	return c.do(ctx, http.MethodPatch, "/scanners/"+url.PathEscape(uuid),
+		map[string]bool{"is_default": true}, nil)
+}
+
+// DeleteScannerRegistration removes the scanner registration with the given UUID.
+func (c *APIClient) DeleteScannerRegistration(ctx context.Context, uuid string) error {
+	return c.do(ctx, http.MethodDelete, "/scanners/"+url.PathEscape(uuid), nil, nil)
+}
+
+// GetScannerMetadata returns the metadata reported by the scanner adapter of the registration with the given UUID.
+func (c *APIClient) GetScannerMetadata(ctx context.Context, uuid string) (*model.ScannerAdapterMetadata, error) {
+	var metadata model.ScannerAdapterMetadata
+
+	if err := c.do(ctx, http.MethodGet, "/scanners/"+url.PathEscape(uuid)+"/metadata", nil, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// ToScannerRegistrationReq constructs the request registering a scanner adapter from the spec of a scanner,
+// using the given access credential.
+func ToScannerRegistrationReq(spec *v1alpha2.ScannerSpec, accessCredential string) *model.ScannerRegistrationReq {
+	name := spec.Name
+	adapterURL := strfmt.URI(spec.URL)
+
+	req := model.ScannerRegistrationReq{
+		Description:     spec.Description,
+		Disabled:        &spec.Disabled,
+		Name:            &name,
+		SkipCertVerify:  &spec.SkipCertVerify,
+		URL:             &adapterURL,
+		UseInternalAddr: &spec.UseInternalAddr,
+	}
+
+	if spec.Auth != nil {
+		req.Auth = string(spec.Auth.Type)
+		req.AccessCredential = accessCredential
+	}
+
+	return &req
+}
+
+// ScannerRegistrationMatches returns true if a scanner registration held by Harbor matches the given request.
+// The access credential is not compared, as it is not necessarily returned by Harbor.
+func ScannerRegistrationMatches(held *model.ScannerRegistration, req *model.ScannerRegistrationReq) bool {
+	return held.Description == req.Description &&
+		held.URL.String() == req.URL.String() &&
+		held.Auth == req.Auth &&
+		boolValue(held.Disabled) == boolValue(req.Disabled) &&
+		boolValue(held.SkipCertVerify) == boolValue(req.SkipCertVerify) &&
+		boolValue(held.UseInternalAddr) == boolValue(req.UseInternalAddr)
+}
+
+// ScannerRegistrationHash returns a hash of a scanner registration request. The access credential is left out
+// and represented by the resource version of the secret holding it instead, so the hash can be published.
+func ScannerRegistrationHash(req *model.ScannerRegistrationReq, credentialVersion string) (string, error) {
+	withoutCredential := *req
+	withoutCredential.AccessCredential = ""
+
+	hash, err := helper.GenerateHashFromInterfaces([]interface{}{&withoutCredential, credentialVersion})
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// ToScannerMetadata converts the metadata reported by a scanner adapter into the metadata of a scanner status.
+func ToScannerMetadata(metadata *model.ScannerAdapterMetadata) *v1alpha2.ScannerMetadata {
+	if metadata == nil {
+		return nil
+	}
+
+	result := v1alpha2.ScannerMetadata{
+		Properties: metadata.Properties,
+	}
+
+	if metadata.Scanner != nil {
+		result.Name = metadata.Scanner.Name
+		result.Vendor = metadata.Scanner.Vendor
+		result.Version = metadata.Scanner.Version
+	}
+
+	for _, capability := range metadata.Capabilities {
+		if capability == nil {
+			continue
+		}
+
+		result.Capabilities = append(result.Capabilities, v1alpha2.ScannerCapability{
+			ConsumesMimeTypes: capability.ConsumesMimeTypes,
+			ProducesMimeTypes: capability.ProducesMimeTypes,
+		})
+	}
+
+	return &result
+}
+
+// boolValue returns the value of a bool pointer, defaulting to false.
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
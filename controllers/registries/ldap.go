@@ -0,0 +1,99 @@
+package registries
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileLDAP syncs the LDAP authentication settings of an instance and tests the connection to the LDAP server.
+// The results are reflected in the "LDAPSynced" and "LDAPReachable" conditions of the instance.
+func (r *InstanceReconciler) reconcileLDAP(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if harbor.Spec.LDAP == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionLDAPSynced)
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionLDAPReachable)
+		harbor.Status.LDAPConfigurationHash = ""
+
+		return nil
+	}
+
+	bindPassword, secretVersion, err := r.ldapBindPassword(ctx, harbor)
+	if err == nil {
+		err = r.syncLDAP(ctx, harbor, bindPassword, secretVersion)
+	}
+
+	if err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonLDAPSyncFailed, err.Error())
+		return err
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonLDAPSynced, "LDAP settings are up to date")
+
+	r.pingLDAP(ctx, harbor, bindPassword)
+
+	return nil
+}
+
+// ldapBindPassword returns the password of the user binding to the LDAP server of an instance,
+// alongside the resource version of the secret holding it.
+func (r *InstanceReconciler) ldapBindPassword(ctx context.Context, harbor *v1alpha2.Instance) (string, string, error) {
+	if harbor.Spec.LDAP.BindPasswordRef == nil {
+		return "", "", nil
+	}
+
+	return helper.GetSecretKeySelectorValueAndVersion(ctx, r.Client, harbor.Namespace,
+		harbor.Spec.LDAP.BindPasswordRef)
+}
+
+// syncLDAP compares the LDAP settings of an instance to the user defined settings and updates them accordingly.
+// As the bind password cannot be read from the Harbor API, the settings are also re-applied whenever
+// the hash of the user defined settings, covering the resource version of the bind password secret, changes.
+func (r *InstanceReconciler) syncLDAP(ctx context.Context, harbor *v1alpha2.Instance,
+	bindPassword, secretVersion string) error {
+	cfg := internal.LDAPConfigurations(harbor.Spec.LDAP, bindPassword)
+
+	hash, err := r.applyManagedConfiguration(ctx, harbor, cfg, harbor.Status.LDAPConfigurationHash, secretVersion,
+		internal.LDAPSearchPasswordKey)
+	if err != nil {
+		return fmt.Errorf("applying LDAP settings failed: %w", err)
+	}
+
+	harbor.Status.LDAPConfigurationHash = hash
+
+	return nil
+}
+
+// pingLDAP tests the connection of Harbor to the LDAP server of an instance
+// and reflects the result in the "LDAPReachable" condition of the instance.
+func (r *InstanceReconciler) pingLDAP(ctx context.Context, harbor *v1alpha2.Instance, bindPassword string) {
+	apiClient, err := r.HarborClients.GetAPIClient(ctx, r.Client, harbor)
+	if err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPReachable, metav1.ConditionUnknown,
+			v1alpha2.InstanceReasonAPIUnreachable, err.Error())
+		return
+	}
+
+	result, err := apiClient.PingLDAP(ctx, internal.LDAPConf(harbor.Spec.LDAP, bindPassword))
+	if err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPReachable, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonLDAPPingFailed, err.Error())
+		return
+	}
+
+	if !result.Success {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPReachable, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonLDAPPingFailed, result.Message)
+		return
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionLDAPReachable, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonLDAPPingSucceeded, "harbor successfully connected to the LDAP server")
+}
@@ -0,0 +1,61 @@
+package registries
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileOIDC syncs the OIDC authentication settings of an instance
+// and reflects the result in the "OIDCSynced" condition of the instance.
+func (r *InstanceReconciler) reconcileOIDC(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if harbor.Spec.OIDC == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionOIDCSynced)
+		harbor.Status.OIDCConfigurationHash = ""
+
+		return nil
+	}
+
+	if err := r.syncOIDC(ctx, harbor); err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionOIDCSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonOIDCSyncFailed, err.Error())
+		return err
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionOIDCSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonOIDCSynced, "OIDC settings are up to date")
+
+	return nil
+}
+
+// syncOIDC compares the OIDC settings of an instance to the user defined settings and updates them accordingly.
+// As the client secret cannot be read from the Harbor API, the settings are also re-applied whenever
+// the hash of the user defined settings, covering the resource version of the client secret, changes.
+func (r *InstanceReconciler) syncOIDC(ctx context.Context, harbor *v1alpha2.Instance) error {
+	clientSecret, secretVersion, err := helper.GetSecretKeySelectorValueAndVersion(ctx, r.Client, harbor.Namespace,
+		&harbor.Spec.OIDC.ClientSecretRef)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := internal.OIDCConfigurations(harbor.Spec.OIDC, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	hash, err := r.applyManagedConfiguration(ctx, harbor, cfg, harbor.Status.OIDCConfigurationHash, secretVersion,
+		internal.OIDCClientSecretKey)
+	if err != nil {
+		return fmt.Errorf("applying OIDC settings failed: %w", err)
+	}
+
+	harbor.Status.OIDCConfigurationHash = hash
+
+	return nil
+}
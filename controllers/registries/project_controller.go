@@ -45,6 +45,7 @@ import (
 	h "github.com/mittwald/goharbor-client/v5/apiv2"
 	"github.com/mittwald/goharbor-client/v5/apiv2/model"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ProjectReconciler reconciles a Project object
@@ -52,6 +53,8 @@ type ProjectReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
 }
 
 var (
@@ -124,7 +127,7 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	// Build a client to connect to the harbor API
-	harborClient, err := internal.BuildClient(ctx, r.Client, harbor)
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -175,6 +178,11 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, err
 		}
 
+		// Revisit the project as soon as an item of its CVE allowlist expires.
+		if expiry := internal.NextCVEAllowlistExpiry(project.Spec.CVEAllowlist, time.Now()); expiry > 0 {
+			return ctrl.Result{RequeueAfter: expiry}, r.Client.Status().Patch(ctx, project, patch)
+		}
+
 	case v1alpha2.ProjectStatusPhaseTerminating:
 		// Delete the project via harbor API
 		err := r.assertDeletedProject(ctx, reqLogger, harborClient, project)
@@ -450,6 +458,25 @@ func (r *ProjectReconciler) ensureProject(ctx context.Context, heldProject *mode
 	}
 
 	project.Status.ID = heldProject.ProjectID
+	project.Status.CVEAllowlist = nil
+
+	// The CVE allowlist of the project is only managed if specified on the project CR.
+	// Otherwise, the allowlist held by Harbor is sent back unchanged.
+	if project.Spec.CVEAllowlist != nil {
+		active, expired := internal.PartitionCVEAllowlist(project.Spec.CVEAllowlist, metav1.Now().Time)
+
+		if !internal.CVEAllowlistMatches(heldProject.CVEAllowlist, active) {
+			r.Log.Info("CVE allowlist of the project drifted, updating it", "project", project.Spec.Name)
+
+			newProject.CVEAllowlist = internal.GenerateProjectCVEAllowlist(heldProject, active)
+		}
+
+		project.Status.CVEAllowlist = &v1alpha2.CVEAllowlistStatus{
+			Active:  active,
+			Expired: expired,
+		}
+	}
+
 	if err := r.Client.Status().Patch(ctx, project, patch); err != nil {
 		return err
 	}
@@ -50,6 +50,8 @@ type RegistryReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
 }
 
 // +kubebuilder:rbac:groups=registries.mittwald.de,resources=registries,verbs=get;list;watch;create;update;patch;delete
@@ -117,7 +119,7 @@ func (r *RegistryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	// Build a client to connect to the harbor API
-	harborClient, err := internal.BuildClient(ctx, r.Client, harbor)
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
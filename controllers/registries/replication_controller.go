@@ -48,6 +48,8 @@ type ReplicationReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
 }
 
 // +kubebuilder:rbac:groups=registries.mittwald.de,resources=replications,verbs=get;list;watch;create;update;patch;delete
@@ -113,7 +115,7 @@ func (r *ReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Build a client to connect to the harbor API
-	harborClient, err := internal.BuildClient(ctx, r.Client, harbor)
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
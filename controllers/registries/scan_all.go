@@ -0,0 +1,106 @@
+package registries
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/mittwald/goharbor-client/v5/apiv2/model"
+	clienterrors "github.com/mittwald/goharbor-client/v5/apiv2/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+// reconcileScanAll syncs the scan all schedule of an instance and records the latest execution of the scan all job
+// in the instance status. The result is reflected in the "ScanAllSynced" condition of the instance.
+func (r *InstanceReconciler) reconcileScanAll(ctx context.Context, harbor *v1alpha2.Instance) error {
+	if harbor.Spec.ScanAll == nil {
+		meta.RemoveStatusCondition(&harbor.Status.Conditions, v1alpha2.InstanceConditionScanAllSynced)
+		harbor.Status.ScanAll = nil
+
+		return nil
+	}
+
+	if err := r.syncScanAllSchedule(ctx, harbor); err != nil {
+		setInstanceCondition(harbor, v1alpha2.InstanceConditionScanAllSynced, metav1.ConditionFalse,
+			v1alpha2.InstanceReasonScheduleSyncFailed, err.Error())
+		return err
+	}
+
+	setInstanceCondition(harbor, v1alpha2.InstanceConditionScanAllSynced, metav1.ConditionTrue,
+		v1alpha2.InstanceReasonScheduleSynced, "scan all schedule is up to date")
+
+	r.updateScanAllStatus(ctx, harbor)
+
+	return nil
+}
+
+// syncScanAllSchedule reads the state of a configured scan all schedule and compares it to the user
+// defined scan all schedule.
+func (r *InstanceReconciler) syncScanAllSchedule(ctx context.Context, harbor *v1alpha2.Instance) error {
+	scheduleType, err := enumScheduleType(harbor.Spec.ScanAll.ScheduleType)
+	if err != nil {
+		return err
+	}
+
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return err
+	}
+
+	newSchedule := model.Schedule{
+		Schedule: &model.ScheduleObj{
+			Cron: harbor.Spec.ScanAll.Cron,
+			Type: string(scheduleType),
+		},
+	}
+
+	schedule, err := harborClient.GetScanAllSchedule(ctx)
+
+	var notFound *clienterrors.ErrNotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+
+	if err != nil || schedule.Schedule == nil {
+		// The initial scan all schedule is undefined, set it to the desired schedule.
+		if scheduleType == v1alpha2.ScheduleTypeNone {
+			return nil
+		}
+
+		return harborClient.CreateScanAllSchedule(ctx, &newSchedule)
+	}
+
+	// Compare the constructed schedule to the existing one, ignoring read-only fields, and update accordingly
+	current := &model.ScheduleObj{
+		Cron: schedule.Schedule.Cron,
+		Type: schedule.Schedule.Type,
+	}
+
+	if !reflect.DeepEqual(newSchedule.Schedule, current) {
+		return harborClient.UpdateScanAllSchedule(ctx, &newSchedule)
+	}
+
+	return nil
+}
+
+// updateScanAllStatus records the latest execution of the scan all job in the status of an instance.
+// The previous status is kept if the metrics of the scan all job cannot be obtained.
+func (r *InstanceReconciler) updateScanAllStatus(ctx context.Context, harbor *v1alpha2.Instance) {
+	apiClient, err := r.HarborClients.GetAPIClient(ctx, r.Client, harbor)
+	if err != nil {
+		r.Log.Error(err, "building harbor API client failed", "instance", harbor.Name)
+		return
+	}
+
+	stats, err := apiClient.GetScanAllMetrics(ctx)
+	if err != nil {
+		r.Log.Error(err, "fetching scan all metrics failed", "instance", harbor.Name)
+		return
+	}
+
+	harbor.Status.ScanAll = internal.ToScanAllStatus(stats, metav1.Now())
+}
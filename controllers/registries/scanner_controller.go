@@ -0,0 +1,296 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	controllererrors "github.com/mittwald/harbor-operator/controllers/registries/errors"
+	"github.com/mittwald/harbor-operator/controllers/registries/helper"
+	"github.com/mittwald/harbor-operator/controllers/registries/internal"
+)
+
+const (
+	// scannerHealthy is the health reported for scanners whose adapter metadata could be fetched.
+	scannerHealthy = "healthy"
+	// scannerUnhealthy is the health reported for scanners whose adapter metadata could not be fetched.
+	scannerUnhealthy = "unhealthy"
+)
+
+// ScannerReconciler reconciles a Scanner object
+type ScannerReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
+}
+
+// +kubebuilder:rbac:groups=registries.mittwald.de,resources=scanners,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=registries.mittwald.de,resources=scanners/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *ScannerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("scanner", req.NamespacedName)
+	reqLogger.Info("Reconciling Scanner")
+
+	// Fetch the Scanner instance
+	scanner := &v1alpha2.Scanner{}
+
+	err := r.Client.Get(ctx, req.NamespacedName, scanner)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	original := scanner.DeepCopy()
+	patch := client.MergeFrom(original)
+
+	if scanner.ObjectMeta.DeletionTimestamp != nil &&
+		scanner.Status.Phase != v1alpha2.ScannerStatusPhaseTerminating {
+		scanner.Status = v1alpha2.ScannerStatus{Phase: v1alpha2.ScannerStatusPhaseTerminating}
+
+		return ctrl.Result{}, r.Client.Status().Patch(ctx, scanner, patch)
+	}
+
+	// Fetch the goharbor instance if it exists and is properly set up.
+	// If the above does not apply, pull the finalizer from the scanner object.
+	harbor, err := internal.GetOperationalHarborInstance(ctx, client.ObjectKey{
+		Namespace: scanner.Namespace,
+		Name:      scanner.Spec.ParentInstance.Name,
+	}, r.Client)
+	if err != nil {
+		switch err.Error() {
+		case controllererrors.ErrInstanceNotInstalledMsg:
+			reqLogger.Info("waiting till harbor instance is installed")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		case controllererrors.ErrInstanceNotFoundMsg:
+			controllerutil.RemoveFinalizer(scanner, internal.FinalizerName)
+			if err := r.Client.Patch(ctx, scanner, patch); err != nil {
+				return ctrl.Result{}, err
+			}
+			fallthrough
+		default:
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Set OwnerReference to the parent harbor instance
+	err = ctrl.SetControllerReference(harbor, scanner, r.Scheme)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !reflect.DeepEqual(original.ObjectMeta.OwnerReferences, scanner.ObjectMeta.OwnerReferences) {
+		if err := r.Client.Patch(ctx, scanner, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Build a client to connect to the harbor API
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Check the Harbor API if it's reporting as healthy
+	err = internal.AssertHealthyHarborInstance(ctx, harborClient)
+	if err != nil {
+		reqLogger.Info("waiting till harbor instance is healthy")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// The scanner endpoints are not covered by the REST client
+	apiClient, err := r.HarborClients.GetAPIClient(ctx, r.Client, harbor)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch scanner.Status.Phase {
+	default:
+		return ctrl.Result{}, nil
+
+	case v1alpha2.ScannerStatusPhaseUnknown:
+		scanner.Status.Phase = v1alpha2.ScannerStatusPhaseCreating
+		scanner.Status.Message = "scanner is about to be registered"
+
+		return ctrl.Result{}, r.Client.Status().Patch(ctx, scanner, patch)
+
+	case v1alpha2.ScannerStatusPhaseCreating, v1alpha2.ScannerStatusPhaseReady:
+		controllerutil.AddFinalizer(scanner, internal.FinalizerName)
+		if err := r.Client.Patch(ctx, scanner, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := r.assertExistingScanner(ctx, reqLogger, apiClient, scanner); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		scanner.Status.Phase = v1alpha2.ScannerStatusPhaseReady
+
+		// The health and metadata of the scanner adapter are refreshed periodically.
+		return ctrl.Result{RequeueAfter: healthCheckInterval()}, r.Client.Status().Patch(ctx, scanner, patch)
+
+	case v1alpha2.ScannerStatusPhaseTerminating:
+		// Remove the scanner registration via harbor API
+		if err := r.assertDeletedScanner(ctx, reqLogger, apiClient, scanner); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, r.Client.Patch(ctx, scanner, patch)
+	}
+}
+
+// assertExistingScanner registers the scanner adapter of a scanner at Harbor, or updates an existing registration
+// to match the scanner spec, and writes the state of the registration back to the scanner status.
+func (r *ScannerReconciler) assertExistingScanner(ctx context.Context, log logr.Logger, apiClient *internal.APIClient,
+	scanner *v1alpha2.Scanner) error {
+	accessCredential, credentialVersion, err := r.scannerAccessCredential(ctx, scanner)
+	if err != nil {
+		return err
+	}
+
+	req := internal.ToScannerRegistrationReq(&scanner.Spec, accessCredential)
+
+	// As the access credential is not necessarily returned by the Harbor API, the registration is also updated
+	// whenever the hash of the request, covering the resource version of the credential secret, changes.
+	hash, err := internal.ScannerRegistrationHash(req, credentialVersion)
+	if err != nil {
+		return err
+	}
+
+	held, err := apiClient.GetScannerRegistrationByName(ctx, scanner.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	if held == nil {
+		log.Info("registering scanner", "url", scanner.Spec.URL)
+
+		if err := apiClient.NewScannerRegistration(ctx, req); err != nil {
+			return err
+		}
+
+		held, err = apiClient.GetScannerRegistrationByName(ctx, scanner.Spec.Name)
+		if err != nil {
+			return err
+		}
+
+		if held == nil {
+			return fmt.Errorf("scanner %q could not be found after registering it", scanner.Spec.Name)
+		}
+	} else if !internal.ScannerRegistrationMatches(held, req) || scanner.Status.SpecHash != hash {
+		log.Info("updating scanner registration", "uuid", held.UUID)
+
+		if err := apiClient.UpdateScannerRegistration(ctx, held.UUID, req); err != nil {
+			return err
+		}
+	}
+
+	isDefault := held.IsDefault != nil && *held.IsDefault
+
+	if scanner.Spec.Default && !isDefault {
+		log.Info("marking scanner as default", "uuid", held.UUID)
+
+		if err := apiClient.SetDefaultScannerRegistration(ctx, held.UUID); err != nil {
+			return err
+		}
+
+		isDefault = true
+	}
+
+	scanner.Status.UUID = held.UUID
+	scanner.Status.SpecHash = hash
+	scanner.Status.Default = isDefault
+	scanner.Status.Message = ""
+
+	metadata, err := apiClient.GetScannerMetadata(ctx, held.UUID)
+	if err != nil {
+		// An unreachable scanner adapter does not prevent its registration.
+		scanner.Status.Health = scannerUnhealthy
+		scanner.Status.Message = fmt.Sprintf("fetching scanner metadata failed: %s", err)
+
+		return nil
+	}
+
+	scanner.Status.Health = scannerHealthy
+	scanner.Status.Metadata = internal.ToScannerMetadata(metadata)
+
+	return nil
+}
+
+// scannerAccessCredential returns the credential used by Harbor to authenticate against the scanner adapter,
+// alongside the resource version of the secret holding it.
+func (r *ScannerReconciler) scannerAccessCredential(ctx context.Context,
+	scanner *v1alpha2.Scanner) (string, string, error) {
+	if scanner.Spec.Auth == nil {
+		return "", "", nil
+	}
+
+	return helper.GetSecretKeySelectorValueAndVersion(ctx, r.Client, scanner.Namespace,
+		&scanner.Spec.Auth.AccessCredentialRef)
+}
+
+// assertDeletedScanner removes the registration of a scanner from Harbor and pulls the finalizer of the scanner.
+func (r *ScannerReconciler) assertDeletedScanner(ctx context.Context, log logr.Logger, apiClient *internal.APIClient,
+	scanner *v1alpha2.Scanner) error {
+	held, err := apiClient.GetScannerRegistrationByName(ctx, scanner.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	if held != nil {
+		err := apiClient.DeleteScannerRegistration(ctx, held.UUID)
+
+		var apiErr *internal.APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+			return err
+		}
+	} else {
+		log.Info("scanner does not exist on the server side")
+	}
+
+	log.Info("pulling finalizer")
+	controllerutil.RemoveFinalizer(scanner, internal.FinalizerName)
+
+	return nil
+}
+
+func (r *ScannerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha2.Scanner{}).
+		Complete(r)
+}
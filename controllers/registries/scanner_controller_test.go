@@ -0,0 +1,44 @@
+package registries_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	registriestesting "github.com/mittwald/harbor-operator/controllers/registries/testing"
+)
+
+var _ = Describe("ScannerController", func() {
+	BeforeEach(func() {
+		name = testScannerName
+		namespace = testNamespaceName
+		request = ctrl.Request{
+			NamespacedName: client.ObjectKey{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	})
+	Describe("Create, Get and Delete", func() {
+		var scanner *v1alpha2.Scanner
+		Context("Scanner", func() {
+			BeforeEach(func() {
+				scanner = registriestesting.CreateScanner(name, namespace, "")
+				Ω(k8sClient.Create(ctx, scanner)).Should(Succeed())
+				Ω(k8sClient.Get(ctx, client.ObjectKey{
+					Name:      name,
+					Namespace: namespace,
+				},
+					scanner)).Should(Succeed())
+			})
+			AfterEach(func() {
+				Ω(k8sClient.Delete(ctx, scanner)).Should(Succeed())
+			})
+			It("Should not be nil", func() {
+				Ω(scanner).ToNot(BeNil())
+			})
+		})
+	})
+})
@@ -56,6 +56,7 @@ var (
 	testRegistryName                = "test-registry"
 	testUserName                    = "test-user"
 	testReplicationName             = "test-replication"
+	testScannerName                 = "test-scanner"
 	testNamespaceName               = "test-namespace"
 	ctx                             = context.TODO()
 	cancel                          context.CancelFunc
@@ -0,0 +1,43 @@
+package testing
+
+import (
+	"context"
+
+	helmclient "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// FakeHelmClient is a helm client serving a single release.
+// Install and upgrade operations fail with InstallErr, if set, and return the release otherwise.
+// Methods that are not implemented panic.
+type FakeHelmClient struct {
+	helmclient.Client
+
+	Release    *release.Release
+	InstallErr error
+}
+
+// UpdateChartRepos does nothing.
+func (c *FakeHelmClient) UpdateChartRepos() error {
+	return nil
+}
+
+// InstallOrUpgradeChart returns the release of the client, or InstallErr if set.
+func (c *FakeHelmClient) InstallOrUpgradeChart(_ context.Context, _ *helmclient.ChartSpec,
+	_ *helmclient.GenericHelmOptions) (*release.Release, error) {
+	if c.InstallErr != nil {
+		return nil, c.InstallErr
+	}
+
+	return c.Release, nil
+}
+
+// GetRelease returns the release of the client, regardless of the given name.
+func (c *FakeHelmClient) GetRelease(_ string) (*release.Release, error) {
+	if c.Release == nil {
+		return nil, driver.ErrReleaseNotFound
+	}
+
+	return c.Release, nil
+}
@@ -0,0 +1,26 @@
+package testing
+
+import (
+	"github.com/mittwald/harbor-operator/apis/registries/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateScanner returns a scanner object with sample values.
+func CreateScanner(name, namespace, instanceRef string) *v1alpha2.Scanner {
+	s := v1alpha2.Scanner{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha2.ScannerSpec{
+			Name:           "test-scanner",
+			Description:    "test scanner",
+			URL:            "http://harbor-scanner-trivy:8080",
+			Default:        true,
+			ParentInstance: corev1.LocalObjectReference{Name: instanceRef},
+		},
+	}
+
+	return &s
+}
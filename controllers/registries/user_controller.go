@@ -48,6 +48,8 @@ type UserReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// HarborClients caches the harbor API clients of instances.
+	HarborClients *HarborClientCache
 }
 
 const (
@@ -132,7 +134,7 @@ func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res c
 	}
 
 	// Build a client to connect to the harbor API
-	harborClient, err := internal.BuildClient(ctx, r.Client, harbor)
+	harborClient, err := r.HarborClients.Get(ctx, r.Client, harbor)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
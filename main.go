@@ -64,6 +64,10 @@ func main() {
 		"/tmp/.helmcache", "helm client repository cache path")
 	pflag.String(config.FlagHelmClientRepoConfPath,
 		"/tmp/.helmconfig", "helm client repository config path")
+	pflag.Duration(config.FlagHealthCheckInterval, config.DefaultHealthCheckInterval,
+		"interval in which the health of installed harbor instances is checked")
+	pflag.Duration(config.FlagPendingReleaseTimeout, config.DefaultPendingReleaseTimeout,
+		"duration after which helm releases stuck in a pending state are recovered")
 
 	pflag.Parse()
 
@@ -95,6 +99,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The harbor API clients are shared between all reconcilers.
+	harborClients := controllers.NewHarborClientCache()
+
 	if err = (&controllers.InstanceChartRepositoryReconciler{
 		Client:             mgr.GetClient(),
 		Log:                ctrl.Log.WithName("controllers").WithName("registries").WithName("InstanceChartRepository"),
@@ -109,42 +116,57 @@ func main() {
 		Log:                ctrl.Log.WithName("controllers").WithName("registries").WithName("Instance"),
 		Scheme:             mgr.GetScheme(),
 		HelmClientReceiver: AddHelmClientReceiver(mgr),
+		HarborClients:      harborClients,
+		Recorder:           mgr.GetEventRecorderFor("instance-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Instance")
 		os.Exit(1)
 	}
 	if err = (&controllers.RegistryReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("registries").WithName("Registry"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("registries").WithName("Registry"),
+		Scheme:        mgr.GetScheme(),
+		HarborClients: harborClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Registry")
 		os.Exit(1)
 	}
 	if err = (&controllers.ReplicationReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("registries").WithName("Replication"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("registries").WithName("Replication"),
+		Scheme:        mgr.GetScheme(),
+		HarborClients: harborClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Replication")
 		os.Exit(1)
 	}
 	if err = (&controllers.UserReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("registries").WithName("User"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("registries").WithName("User"),
+		Scheme:        mgr.GetScheme(),
+		HarborClients: harborClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "User")
 		os.Exit(1)
 	}
 	if err = (&controllers.ProjectReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("registries").WithName("Project"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("registries").WithName("Project"),
+		Scheme:        mgr.GetScheme(),
+		HarborClients: harborClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Project")
 		os.Exit(1)
 	}
+	if err = (&controllers.ScannerReconciler{
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("registries").WithName("Scanner"),
+		Scheme:        mgr.GetScheme(),
+		HarborClients: harborClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Scanner")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")